@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/songzhibin97/mini-interpreter/token"
@@ -66,6 +67,46 @@ func (v VarStmt) String() string {
 
 // ============================================================================
 
+// const <标识符> = <表达式>
+// const ( <标识符> = <表达式>; <标识符>; ... )
+
+type ConstStmt struct {
+	Token   *token.Token
+	Specs   []*ConstSpec
+	Grouped bool // 以括号分组的声明
+}
+
+func (c ConstStmt) TokenValue() string { return c.Token.Value }
+func (c ConstStmt) stmtNode()          {}
+func (c ConstStmt) String() string {
+	specs := make([]string, 0, len(c.Specs))
+	for _, spec := range c.Specs {
+		specs = append(specs, spec.String())
+	}
+	if c.Grouped {
+		return c.TokenValue() + " (" + strings.Join(specs, "; ") + ")"
+	}
+	return c.TokenValue() + " " + strings.Join(specs, "; ")
+}
+
+// ConstSpec 常量声明中的一项,分组声明中省略表达式时重复上一个表达式
+type ConstSpec struct {
+	Name     *Identifier
+	Value    Expr
+	Iota     int  // 在分组声明中的序号
+	Implicit bool // Value 是重复的上一个表达式
+}
+
+func (c ConstSpec) TokenValue() string { return c.Name.TokenValue() }
+func (c ConstSpec) String() string {
+	if c.Implicit {
+		return c.Name.String()
+	}
+	return c.Name.String() + " = " + c.Value.String()
+}
+
+// ============================================================================
+
 // return <表达式>
 
 type ReturnStmt struct {
@@ -102,6 +143,209 @@ func (e ExprStmt) String() string {
 
 // ============================================================================
 
+// <标识符> := <表达式>
+// <标识符或索引表达式> = <表达式>
+// <标识符或索引表达式> op= <表达式>
+// a, b := 1, 2
+// a, b = b, a
+
+type AssignStmt struct {
+	Token   *token.Token // 赋值运算符,如 =、:= 或 +=
+	Targets []Expr       // *Identifier 或 *IndexExpr, := 时只能是 *Identifier
+	Values  []Expr       // 与 Targets 一一对应
+}
+
+func (a AssignStmt) TokenValue() string { return a.Token.Value }
+func (a AssignStmt) stmtNode()          {}
+func (a AssignStmt) String() string {
+	var targets, values []string
+	for _, target := range a.Targets {
+		targets = append(targets, target.String())
+	}
+	for _, value := range a.Values {
+		if value != nil {
+			values = append(values, value.String())
+		}
+	}
+	return strings.Join(targets, ", ") + " " + a.TokenValue() + " " + strings.Join(values, ", ")
+}
+
+// ============================================================================
+
+// <标识符或索引表达式>++ 或 <标识符或索引表达式>--
+
+type IncDecStmt struct {
+	Token  *token.Token // ++ 或 --
+	Target Expr         // *Identifier 或 *IndexExpr
+}
+
+func (i IncDecStmt) TokenValue() string { return i.Token.Value }
+func (i IncDecStmt) stmtNode()          {}
+func (i IncDecStmt) String() string     { return i.Target.String() + i.TokenValue() }
+
+// ============================================================================
+
+// for { <语句块> }
+// for <条件> { <语句块> }
+// for <初始化语句>; <条件>; <后置语句> { <语句块> }
+
+type ForStmt struct {
+	Token *token.Token
+	Init  Stmt // 可以为 nil
+	Cond  Expr // 可以为 nil,表示无限循环
+	Post  Stmt // 可以为 nil
+	Body  *BlockStmt
+}
+
+func (f ForStmt) TokenValue() string { return f.Token.Value }
+func (f ForStmt) stmtNode()          {}
+func (f ForStmt) String() string {
+	var b strings.Builder
+	b.WriteString(f.TokenValue() + " ")
+	if f.Init != nil || f.Post != nil {
+		if f.Init != nil {
+			b.WriteString(f.Init.String())
+		}
+		b.WriteString("; ")
+		if f.Cond != nil {
+			b.WriteString(f.Cond.String())
+		}
+		b.WriteString("; ")
+		if f.Post != nil {
+			b.WriteString(f.Post.String())
+		}
+		b.WriteString(" ")
+	} else if f.Cond != nil {
+		b.WriteString(f.Cond.String() + " ")
+	}
+	b.WriteString(f.Body.String())
+	return b.String()
+}
+
+// ============================================================================
+
+// for <键>, <值> := range <表达式> { <语句块> }
+
+type RangeStmt struct {
+	Token *token.Token // for
+	Key   Expr         // 可以为 nil
+	Value Expr         // 可以为 nil
+	Tok   *token.Token // := 或 =, Key 为 nil 时为 nil
+	X     Expr         // 被遍历的数组、字符串或 map
+	Body  *BlockStmt
+}
+
+func (r RangeStmt) TokenValue() string { return r.Token.Value }
+func (r RangeStmt) stmtNode()          {}
+func (r RangeStmt) String() string {
+	var b strings.Builder
+	b.WriteString(r.TokenValue() + " ")
+	if r.Key != nil {
+		b.WriteString(r.Key.String())
+		if r.Value != nil {
+			b.WriteString(", " + r.Value.String())
+		}
+		b.WriteString(" " + r.Tok.Value + " ")
+	}
+	b.WriteString("range " + r.X.String() + " " + r.Body.String())
+	return b.String()
+}
+
+// ============================================================================
+
+// break <标签>
+// continue <标签>
+
+type BranchStmt struct {
+	Token *token.Token // break、continue 或 fallthrough
+	Label *Identifier  // 可以为 nil
+}
+
+func (b BranchStmt) TokenValue() string { return b.Token.Value }
+func (b BranchStmt) stmtNode()          {}
+func (b BranchStmt) String() string {
+	if b.Label != nil {
+		return b.TokenValue() + " " + b.Label.String()
+	}
+	return b.TokenValue()
+}
+
+// ============================================================================
+
+// <标签>: <语句>
+
+type LabeledStmt struct {
+	Token *token.Token // 标签
+	Label *Identifier
+	Stmt  Stmt
+}
+
+func (l LabeledStmt) TokenValue() string { return l.Token.Value }
+func (l LabeledStmt) stmtNode()          {}
+func (l LabeledStmt) String() string     { return l.Label.String() + ": " + l.Stmt.String() }
+
+// ============================================================================
+
+// switch <初始化语句>; <表达式> { case <表达式列表>: <语句列表> default: <语句列表> }
+
+type SwitchStmt struct {
+	Token *token.Token
+	Init  Stmt // 可以为 nil
+	Tag   Expr // 可以为 nil,此时相当于 switch true
+	Body  []*CaseClause
+}
+
+func (s SwitchStmt) TokenValue() string { return s.Token.Value }
+func (s SwitchStmt) stmtNode()          {}
+func (s SwitchStmt) String() string {
+	var b strings.Builder
+	b.WriteString(s.TokenValue() + " ")
+	if s.Init != nil {
+		b.WriteString(s.Init.String() + "; ")
+	}
+	if s.Tag != nil {
+		b.WriteString(s.Tag.String() + " ")
+	}
+	clauses := make([]string, 0, len(s.Body))
+	for _, clause := range s.Body {
+		clauses = append(clauses, clause.String())
+	}
+	b.WriteString(strings.Join(clauses, " "))
+	return b.String()
+}
+
+// ============================================================================
+
+// case <表达式列表>: <语句列表>
+// default: <语句列表>
+
+type CaseClause struct {
+	Token *token.Token // case 或 default
+	List  []Expr       // default 时为 nil
+	Body  []Stmt
+}
+
+func (c CaseClause) TokenValue() string { return c.Token.Value }
+func (c CaseClause) stmtNode()          {}
+func (c CaseClause) String() string {
+	var b strings.Builder
+	b.WriteString(c.TokenValue())
+	if c.List != nil {
+		list := make([]string, 0, len(c.List))
+		for _, expr := range c.List {
+			list = append(list, expr.String())
+		}
+		b.WriteString(" " + strings.Join(list, ", "))
+	}
+	b.WriteString(": ")
+	for _, stmt := range c.Body {
+		b.WriteString(stmt.String())
+	}
+	return b.String()
+}
+
+// ============================================================================
+
 type BlockStmt struct {
 	Token *token.Token
 	Stmts []Stmt
@@ -153,6 +397,17 @@ func (i Integer) String() string     { return i.Token.Value }
 
 // ============================================================================
 
+type Float struct {
+	Token *token.Token
+	Value float64
+}
+
+func (f Float) TokenValue() string { return f.Token.Value }
+func (f Float) exprNode()          {}
+func (f Float) String() string     { return f.Token.Value }
+
+// ============================================================================
+
 type String struct {
 	Token *token.Token
 	Value string
@@ -160,7 +415,60 @@ type String struct {
 
 func (s String) TokenValue() string { return s.Token.Value }
 func (s String) exprNode()          {}
-func (s String) String() string     { return s.Token.Value }
+func (s String) String() string {
+	// ${ 需要转义,否则会被重新解析为插值
+	return strings.ReplaceAll(strconv.Quote(s.Value), "${", `\${`)
+}
+
+// ============================================================================
+
+// "a${<表达式>}b"
+
+type InterpolatedString struct {
+	Token *token.Token // STRING_HEAD
+	Parts []Expr       // 偶数下标为 *String 字面量片段,奇数下标为插值表达式
+}
+
+func (s InterpolatedString) TokenValue() string { return s.Token.Value }
+func (s InterpolatedString) exprNode()          {}
+func (s InterpolatedString) String() string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for i, part := range s.Parts {
+		if i%2 == 1 {
+			b.WriteString("${" + part.String() + "}")
+			continue
+		}
+		q := part.String()
+		b.WriteString(q[1 : len(q)-1])
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// ============================================================================
+
+// <数字>i
+
+type Imag struct {
+	Token *token.Token
+	Value complex128
+}
+
+func (i Imag) TokenValue() string { return i.Token.Value }
+func (i Imag) exprNode()          {}
+func (i Imag) String() string     { return i.Token.Value }
+
+// ============================================================================
+
+type Char struct {
+	Token *token.Token
+	Value rune
+}
+
+func (c Char) TokenValue() string { return c.Token.Value }
+func (c Char) exprNode()          {}
+func (c Char) String() string     { return strconv.QuoteRune(c.Value) }
 
 // ============================================================================
 
@@ -252,20 +560,26 @@ func (i InfixExpr) String() string {
 
 // ============================================================================
 
-//if (<条件>) <结果> else <可替代的结果>
+//if [<初始化语句>;] <条件> <结果> else <可替代的结果>
+//if [<初始化语句>;] <条件> <结果> else if ...
 
 type IfExpr struct {
 	Token       *token.Token
+	Init        Stmt // 可以为 nil,其中声明的变量作用于整个 if/else 链
 	Condition   Expr
 	Consequence *BlockStmt
-	Alternative *BlockStmt
+	Alternative *BlockStmt // else if 时为只包含该 if 的块
 }
 
 func (i IfExpr) TokenValue() string { return i.Token.Value }
 func (i IfExpr) exprNode()          {}
 func (i IfExpr) String() string {
 	var b strings.Builder
-	b.WriteString("if" + i.Condition.String() + " " + i.Consequence.String())
+	b.WriteString("if")
+	if i.Init != nil {
+		b.WriteString(" " + i.Init.String() + "; ")
+	}
+	b.WriteString(i.Condition.String() + " " + i.Consequence.String())
 	if i.Alternative != nil {
 		b.WriteString("else " + i.Alternative.String())
 	}
@@ -277,46 +591,89 @@ func (i IfExpr) String() string {
 // func <参数列表> <块语句>
 
 type FuncExpr struct {
-	Token  *token.Token
-	Name   *Identifier
-	Params []*Identifier
-	Body   *BlockStmt
+	Token    *token.Token
+	Name     *Identifier // 匿名函数时为 nil
+	Params   []*Identifier
+	Defaults []Expr // 与 Params 一一对应,没有默认值的参数为 nil;所有参数都没有默认值时整体为 nil
+	Variadic bool   // 最后一个参数以 ... 结尾,收集多余的实参
+	Body     *BlockStmt
 }
 
 func (f FuncExpr) TokenValue() string { return f.Token.Value }
 func (f FuncExpr) exprNode()          {}
 func (f FuncExpr) String() string {
 	params := make([]string, 0, len(f.Params))
-	for _, param := range f.Params {
+	for i, param := range f.Params {
+		if f.Defaults != nil && f.Defaults[i] != nil {
+			params = append(params, param.String()+" = "+f.Defaults[i].String())
+			continue
+		}
 		params = append(params, param.String())
 	}
+	if f.Variadic {
+		params[len(params)-1] += "..."
+	}
 
+	if f.Name == nil {
+		return f.TokenValue() + "(" + strings.Join(params, ", ") + ") " + f.Body.String()
+	}
 	return f.TokenValue() + " " + f.Name.String() + " " + "(" + strings.Join(params, ", ") + ") " + f.Body.String()
 }
 
 // ============================================================================
 
-// <表达式>(<以逗号分隔的表达式列表>)
+// <表达式>...
+// 只能出现在调用参数与数组字面量中,展开数组的元素
+
+type SpreadExpr struct {
+	Token *token.Token // ...
+	Value Expr
+}
+
+func (s SpreadExpr) TokenValue() string { return s.Token.Value }
+func (s SpreadExpr) exprNode()          {}
+func (s SpreadExpr) String() string     { return s.Value.String() + s.TokenValue() }
+
+// ============================================================================
+
+// <表达式>(<以逗号分隔的表达式列表>, <参数名>: <表达式>)
 
 type CallExpr struct {
-	Token *token.Token
-	Func  Expr
-	Args  []Expr
+	Token    *token.Token
+	Func     Expr
+	Args     []Expr
+	Keywords []*KeywordArg // 关键字参数,位于位置参数之后
 }
 
 func (c CallExpr) TokenValue() string { return c.Token.Value }
 func (c CallExpr) exprNode()          {}
 func (c CallExpr) String() string {
-	args := make([]string, 0, len(c.Args))
+	args := make([]string, 0, len(c.Args)+len(c.Keywords))
 	for _, arg := range c.Args {
 		args = append(args, arg.String())
 	}
+	for _, keyword := range c.Keywords {
+		args = append(args, keyword.String())
+	}
 
-	return c.Func.TokenValue() + "(" + strings.Join(args, ", ") + ")"
+	return c.Func.String() + "(" + strings.Join(args, ", ") + ")"
 }
 
 // ============================================================================
 
+// <参数名>: <表达式>
+
+type KeywordArg struct {
+	Token *token.Token // 参数名
+	Name  *Identifier
+	Value Expr
+}
+
+func (k KeywordArg) TokenValue() string { return k.Token.Value }
+func (k KeywordArg) String() string     { return k.Name.String() + ": " + k.Value.String() }
+
+// ============================================================================
+
 // <表达式>[<表达式>]
 
 type IndexExpr struct {
@@ -33,3 +33,11 @@ func TestProgram_String(t *testing.T) {
 	}}
 	assert.Equal(t, p.String(), "var test = value")
 }
+
+func TestString_String(t *testing.T) {
+	s := String{Token: &token.Token{Type: token.STRING, Value: "say \"hi\"\n"}, Value: "say \"hi\"\n"}
+	assert.Equal(t, `"say \"hi\"\n"`, s.String())
+
+	s = String{Token: &token.Token{Type: token.STRING, Value: "${x} $y"}, Value: "${x} $y"}
+	assert.Equal(t, `"\${x} $y"`, s.String())
+}
@@ -21,6 +21,64 @@ func DefaultModify(node Node, fn ModifyFunc) Node {
 	case *VarStmt:
 		n.Value, _ = DefaultModify(n.Value, fn).(Expr)
 
+	case *ConstStmt:
+		var prev Expr
+		for _, spec := range n.Specs {
+			// 重复的表达式与上一项共享,只修改一次,并沿用修改后的结果
+			if spec.Implicit {
+				spec.Value = prev
+				continue
+			}
+			spec.Value, _ = DefaultModify(spec.Value, fn).(Expr)
+			prev = spec.Value
+		}
+
+	case *AssignStmt:
+		for i, target := range n.Targets {
+			n.Targets[i], _ = DefaultModify(target, fn).(Expr)
+		}
+		for i, value := range n.Values {
+			n.Values[i], _ = DefaultModify(value, fn).(Expr)
+		}
+
+	case *IncDecStmt:
+		n.Target, _ = DefaultModify(n.Target, fn).(Expr)
+
+	case *ForStmt:
+		if n.Init != nil {
+			n.Init, _ = DefaultModify(n.Init, fn).(Stmt)
+		}
+		if n.Cond != nil {
+			n.Cond, _ = DefaultModify(n.Cond, fn).(Expr)
+		}
+		if n.Post != nil {
+			n.Post, _ = DefaultModify(n.Post, fn).(Stmt)
+		}
+		n.Body, _ = DefaultModify(n.Body, fn).(*BlockStmt)
+
+	case *RangeStmt:
+		n.X, _ = DefaultModify(n.X, fn).(Expr)
+		n.Body, _ = DefaultModify(n.Body, fn).(*BlockStmt)
+
+	case *LabeledStmt:
+		n.Stmt, _ = DefaultModify(n.Stmt, fn).(Stmt)
+
+	case *SwitchStmt:
+		if n.Init != nil {
+			n.Init, _ = DefaultModify(n.Init, fn).(Stmt)
+		}
+		if n.Tag != nil {
+			n.Tag, _ = DefaultModify(n.Tag, fn).(Expr)
+		}
+		for _, clause := range n.Body {
+			for i, expr := range clause.List {
+				clause.List[i], _ = DefaultModify(expr, fn).(Expr)
+			}
+			for i, stmt := range clause.Body {
+				clause.Body[i], _ = DefaultModify(stmt, fn).(Stmt)
+			}
+		}
+
 	case *BlockStmt:
 		for i, statement := range n.Stmts {
 			n.Stmts[i], _ = DefaultModify(statement, fn).(Stmt)
@@ -38,6 +96,9 @@ func DefaultModify(node Node, fn ModifyFunc) Node {
 		n.Index, _ = DefaultModify(n.Index, fn).(Expr)
 
 	case *IfExpr:
+		if n.Init != nil {
+			n.Init, _ = DefaultModify(n.Init, fn).(Stmt)
+		}
 		n.Condition, _ = DefaultModify(n.Condition, fn).(Expr)
 		n.Consequence, _ = DefaultModify(n.Consequence, fn).(*BlockStmt)
 		if n.Alternative != nil {
@@ -48,6 +109,11 @@ func DefaultModify(node Node, fn ModifyFunc) Node {
 		for i, param := range n.Params {
 			n.Params[i], _ = DefaultModify(param, fn).(*Identifier)
 		}
+		for i, value := range n.Defaults {
+			if value != nil {
+				n.Defaults[i], _ = DefaultModify(value, fn).(Expr)
+			}
+		}
 		n.Body, _ = DefaultModify(n.Body, fn).(*BlockStmt)
 
 	case *Array:
@@ -55,6 +121,14 @@ func DefaultModify(node Node, fn ModifyFunc) Node {
 			n.Elements[i], _ = DefaultModify(element, fn).(Expr)
 		}
 
+	case *SpreadExpr:
+		n.Value, _ = DefaultModify(n.Value, fn).(Expr)
+
+	case *InterpolatedString:
+		for i, part := range n.Parts {
+			n.Parts[i], _ = DefaultModify(part, fn).(Expr)
+		}
+
 	case *Map:
 		newElement := make(map[Expr]Expr)
 		for k, v := range n.Elements {
@@ -75,6 +75,18 @@ func TestModify(t *testing.T) {
 				},
 			},
 		},
+		{
+			&IfExpr{
+				Init:        &ExprStmt{Expr: one()},
+				Condition:   one(),
+				Consequence: &BlockStmt{},
+			},
+			&IfExpr{
+				Init:        &ExprStmt{Expr: two()},
+				Condition:   two(),
+				Consequence: &BlockStmt{},
+			},
+		},
 		{
 			&ReturnStmt{Value: one()},
 			&ReturnStmt{Value: two()},
@@ -129,3 +141,28 @@ func TestModify(t *testing.T) {
 		}
 	}
 }
+
+func TestModify_constImplicit(t *testing.T) {
+	value := &Integer{Value: 1}
+	stmt := &ConstStmt{Specs: []*ConstSpec{
+		{Name: &Identifier{Value: "A"}, Value: value},
+		{Name: &Identifier{Value: "B"}, Value: value, Iota: 1, Implicit: true},
+	}}
+	// 替换节点而不是原地修改
+	replaced := 0
+	DefaultModify(stmt, func(node Node) Node {
+		if integer, ok := node.(*Integer); ok {
+			replaced++
+			return &Integer{Value: integer.Value + 1}
+		}
+		return node
+	})
+	if replaced != 1 {
+		t.Errorf("shared value modified %d times, want 1", replaced)
+	}
+	for _, spec := range stmt.Specs {
+		if v := spec.Value.(*Integer).Value; v != 2 {
+			t.Errorf("%s = %d, want 2", spec.Name.Value, v)
+		}
+	}
+}
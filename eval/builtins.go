@@ -2,6 +2,11 @@ package eval
 
 import (
 	"fmt"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/songzhibin97/mini-interpreter/object"
 )
@@ -17,11 +22,115 @@ var builtins = map[string]*object.Builtin{
 		case *object.Array:
 			return &object.Integer{Value: int64(len(arg.Elements))}
 		case *object.Stringer:
-			return &object.Integer{Value: int64(len(arg.Value))}
+			// 与字符串索引保持一致,按字符(rune)计数
+			return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 		default:
 			return &object.Error{Error: fmt.Sprintf("argument to `len` not supported, got %s", args[0].Type())}
 		}
 	}},
+	"int": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			return arg
+		case *object.Char:
+			return &object.Integer{Value: int64(arg.Value)}
+		case *object.Float:
+			return &object.Integer{Value: int64(arg.Value)}
+		case *object.Stringer:
+			v, err := strconv.ParseInt(arg.Value, 0, 64)
+			if err != nil {
+				return &object.Error{Error: fmt.Sprintf("cannot convert %q to INT", arg.Value)}
+			}
+			return &object.Integer{Value: v}
+		default:
+			return &object.Error{Error: fmt.Sprintf("argument to `int` not supported, got %s", args[0].Type())}
+		}
+	}},
+	"char": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		switch arg := args[0].(type) {
+		case *object.Char:
+			return arg
+		case *object.Integer:
+			if arg.Value < 0 || arg.Value > unicode.MaxRune {
+				return &object.Error{Error: fmt.Sprintf("cannot convert %d to CHAR", arg.Value)}
+			}
+			return &object.Char{Value: rune(arg.Value)}
+		case *object.Stringer:
+			if utf8.RuneCountInString(arg.Value) != 1 {
+				return &object.Error{Error: fmt.Sprintf("cannot convert %q to CHAR", arg.Value)}
+			}
+			v, _ := utf8.DecodeRuneInString(arg.Value)
+			return &object.Char{Value: v}
+		default:
+			return &object.Error{Error: fmt.Sprintf("argument to `char` not supported, got %s", args[0].Type())}
+		}
+	}},
+	"string": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		// CHAR 转为单个字符的字符串, INT 转为十进制文本, 其余类型使用 Inspect 结果
+		switch arg := args[0].(type) {
+		case *object.Stringer:
+			return arg
+		default:
+			return &object.Stringer{Value: arg.Inspect()}
+		}
+	}},
+	"complex": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 2 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=2", len(args))}
+		}
+		for _, arg := range args {
+			if arg.Type() != object.INT && arg.Type() != object.FLOAT {
+				return &object.Error{Error: fmt.Sprintf("argument to `complex` not supported, got %s", arg.Type())}
+			}
+		}
+		return &object.Complex{Value: complex(toFloat(args[0]), toFloat(args[1]))}
+	}},
+	"real": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		if !isNumber(args[0]) {
+			return &object.Error{Error: fmt.Sprintf("argument to `real` not supported, got %s", args[0].Type())}
+		}
+		return &object.Float{Value: real(toComplex(args[0]))}
+	}},
+	"imag": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		if !isNumber(args[0]) {
+			return &object.Error{Error: fmt.Sprintf("argument to `imag` not supported, got %s", args[0].Type())}
+		}
+		return &object.Float{Value: imag(toComplex(args[0]))}
+	}},
+	"abs": {Fn: func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want=1", len(args))}
+		}
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			if arg.Value < 0 {
+				return &object.Integer{Value: -arg.Value}
+			}
+			return arg
+		case *object.Float:
+			return &object.Float{Value: math.Abs(arg.Value)}
+		case *object.Complex:
+			// 复数的模
+			return &object.Float{Value: cmplx.Abs(arg.Value)}
+		default:
+			return &object.Error{Error: fmt.Sprintf("argument to `abs` not supported, got %s", args[0].Type())}
+		}
+	}},
 	"print": {
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
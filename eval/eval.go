@@ -2,6 +2,7 @@ package eval
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/songzhibin97/mini-interpreter/token"
 
@@ -30,6 +31,9 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 		return defaultEval(n.Expr, env)
 
 	case *ast.ReturnStmt:
+		if n.Value == nil {
+			return &object.Return{Value: &object.Nil{}}
+		}
 		ret := defaultEval(n.Value, env)
 		if isError(ret) {
 			return ret
@@ -37,12 +41,60 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 		return &object.Return{Value: ret}
 
 	case *ast.VarStmt:
+		if env.IsLocalConst(n.Name.Value) {
+			return constRedeclared(n.Name.Value)
+		}
 		ret := defaultEval(n.Value, env)
 		if isError(ret) {
 			return ret
 		}
+		if ret == nil {
+			return noValue(n.Value)
+		}
 		env.Set(n.Name.Value, ret)
 
+	case *ast.ConstStmt:
+		return evalConstStmt(n, env)
+
+	case *ast.AssignStmt:
+		return evalAssignStmt(n, env)
+
+	case *ast.ForStmt:
+		return evalForStmt(n, "", env)
+
+	case *ast.RangeStmt:
+		return evalRangeStmt(n, "", env)
+
+	case *ast.SwitchStmt:
+		return evalSwitchStmt(n, "", env)
+
+	case *ast.LabeledStmt:
+		switch stmt := n.Stmt.(type) {
+		case *ast.ForStmt:
+			return evalForStmt(stmt, n.Label.Value, env)
+		case *ast.RangeStmt:
+			return evalRangeStmt(stmt, n.Label.Value, env)
+		case *ast.SwitchStmt:
+			return evalSwitchStmt(stmt, n.Label.Value, env)
+		default:
+			return defaultEval(stmt, env)
+		}
+
+	case *ast.BranchStmt:
+		var label string
+		if n.Label != nil {
+			label = n.Label.Value
+		}
+		if n.Token.Type == token.BREAK {
+			return &object.Break{Label: label}
+		}
+		return &object.Continue{Label: label}
+
+	case *ast.IncDecStmt:
+		return evalUpdate(n.Target, env, func(old object.Object) object.Object {
+			return evalInfixExpr(n.TokenValue()[:1], old, &object.Integer{Value: 1})
+		})
+
 	case *ast.PrefixExpr:
 		right := defaultEval(n.Right, env)
 		if isError(right) {
@@ -56,9 +108,17 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 			return left
 		}
 
+		// && 与 || 短路求值
+		switch {
+		case n.Operator == "&&" && !isTruthy(left):
+			return &object.Boolean{Value: false}
+		case n.Operator == "||" && isTruthy(left):
+			return &object.Boolean{Value: true}
+		}
+
 		right := defaultEval(n.Right, env)
-		if isError(left) {
-			return left
+		if isError(right) {
+			return right
 		}
 		return evalInfixExpr(n.Operator, left, right)
 
@@ -69,10 +129,18 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 		obj := &object.Function{
 			Name:       n.Name,
 			Parameters: n.Params,
+			Defaults:   n.Defaults,
+			Variadic:   n.Variadic,
 			Body:       n.Body,
 			Env:        env,
 		}
-		env.Set(n.Name.Value, obj)
+		// 只有具名函数会绑定到当前作用域
+		if n.Name != nil {
+			if env.IsLocalConst(n.Name.Value) {
+				return constRedeclared(n.Name.Value)
+			}
+			env.Set(n.Name.Value, obj)
+		}
 		return obj
 
 	case *ast.CallExpr:
@@ -89,7 +157,15 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return callFunc(fn, args)
+		keywords := make([]keywordArg, 0, len(n.Keywords))
+		for _, keyword := range n.Keywords {
+			value := defaultEval(keyword.Value, env)
+			if isError(value) {
+				return value
+			}
+			keywords = append(keywords, keywordArg{name: keyword.Name.Value, value: value})
+		}
+		return callFunc(fn, args, keywords)
 
 	case *ast.IndexExpr:
 		left := defaultEval(n.Left, env)
@@ -108,9 +184,21 @@ func defaultEval(node ast.Node, env *object.Env) object.Object {
 	case *ast.Integer:
 		return &object.Integer{Value: n.Value}
 
+	case *ast.Float:
+		return &object.Float{Value: n.Value}
+
+	case *ast.Imag:
+		return &object.Complex{Value: n.Value}
+
 	case *ast.String:
 		return &object.Stringer{Value: n.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(n, env)
+
+	case *ast.Char:
+		return &object.Char{Value: n.Value}
+
 	case *ast.Boolean:
 		return &object.Boolean{Value: n.Value}
 
@@ -150,22 +238,227 @@ func evalBlockStmt(block *ast.BlockStmt, env *object.Env) object.Object {
 			continue
 		}
 		switch r.Type() {
-		case object.RETURN, object.ERROR:
+		case object.RETURN, object.ERROR, object.BREAK, object.CONTINUE:
 			return r
 		}
 	}
 	return r
 }
 
+// evalForStmt 初始化语句中定义的变量只在循环内可见
+func evalForStmt(node *ast.ForStmt, label string, env *object.Env) object.Object {
+	env = object.NewEnv(env)
+	if node.Init != nil {
+		if r := defaultEval(node.Init, env); isError(r) {
+			return r
+		}
+	}
+	for {
+		if node.Cond != nil {
+			cond := defaultEval(node.Cond, env)
+			if isError(cond) {
+				return cond
+			}
+			if cond == nil {
+				return noValue(node.Cond)
+			}
+			if !isTruthy(cond) {
+				return nil
+			}
+		}
+		// 每次迭代在新的作用域中执行循环体,循环体中的声明与闭包互不影响
+		if done, r := loopControl(defaultEval(node.Body, object.NewEnv(env)), label); done {
+			return r
+		}
+		if node.Post != nil {
+			if r := defaultEval(node.Post, env); isError(r) {
+				return r
+			}
+		}
+	}
+}
+
+// evalRangeStmt 遍历数组、字符串(按字符)与 map,被遍历的表达式只求值一次
+func evalRangeStmt(node *ast.RangeStmt, label string, env *object.Env) object.Object {
+	x := defaultEval(node.X, env)
+	if isError(x) {
+		return x
+	}
+	if x == nil {
+		return noValue(node.X)
+	}
+
+	env = object.NewEnv(env)
+	iterate := func(key, value object.Object) (bool, object.Object) {
+		// 每次迭代在新的作用域中绑定迭代变量并执行循环体
+		scope := object.NewEnv(env)
+		if node.Key != nil {
+			if r := bindRangeVar(node, node.Key, key, scope); r != nil {
+				return true, r
+			}
+		}
+		if node.Value != nil {
+			if r := bindRangeVar(node, node.Value, value, scope); r != nil {
+				return true, r
+			}
+		}
+		return loopControl(defaultEval(node.Body, scope), label)
+	}
+
+	switch v := x.(type) {
+	case *object.Array:
+		elements := v.Elements
+		for i, element := range elements {
+			if done, r := iterate(&object.Integer{Value: int64(i)}, element); done {
+				return r
+			}
+		}
+	case *object.Stringer:
+		for i, c := range []rune(v.Value) {
+			if done, r := iterate(&object.Integer{Value: int64(i)}, &object.Char{Value: c}); done {
+				return r
+			}
+		}
+	case *object.Map:
+		for _, pair := range v.Elements {
+			if done, r := iterate(pair.Key, pair.Value); done {
+				return r
+			}
+		}
+	default:
+		return &object.Error{Error: fmt.Sprintf("cannot range over %s", x.Type())}
+	}
+	return nil
+}
+
+// bindRangeVar 将本次迭代的键或值绑定到循环变量, := 定义新变量, = 赋值给已有的变量
+func bindRangeVar(node *ast.RangeStmt, target ast.Expr, value object.Object, env *object.Env) object.Object {
+	if node.Tok.Type == token.DEFINE {
+		name := target.(*ast.Identifier).Value
+		if env.IsLocalConst(name) {
+			return constRedeclared(name)
+		}
+		if name != "_" {
+			env.Set(name, value)
+		}
+		return nil
+	}
+	t, err := resolveTarget(target, env)
+	if err != nil {
+		return err
+	}
+	t.set(env, value)
+	return nil
+}
+
+// evalSwitchStmt 按顺序比较各个 case,都不匹配时执行 default,
+// case 以 fallthrough 结尾时继续执行下一个 case 的语句
+func evalSwitchStmt(node *ast.SwitchStmt, label string, env *object.Env) object.Object {
+	env = object.NewEnv(env)
+	if node.Init != nil {
+		if r := defaultEval(node.Init, env); isError(r) {
+			return r
+		}
+	}
+	var tag object.Object = &object.Boolean{Value: true}
+	if node.Tag != nil {
+		if tag = defaultEval(node.Tag, env); isError(tag) {
+			return tag
+		}
+		if tag == nil {
+			return noValue(node.Tag)
+		}
+	}
+
+	start := -1
+	for i := 0; i < len(node.Body) && start < 0; i++ {
+		for _, expr := range node.Body[i].List {
+			v := defaultEval(expr, env)
+			if isError(v) {
+				return v
+			}
+			if v == nil {
+				return noValue(expr)
+			}
+			eq := evalInfixExpr("==", tag, v)
+			if isError(eq) {
+				return eq
+			}
+			if isTruthy(eq) {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		for i, clause := range node.Body {
+			if clause.List == nil {
+				start = i
+			}
+		}
+		if start < 0 {
+			return nil
+		}
+	}
+
+	var r object.Object
+	for i := start; i < len(node.Body); i++ {
+		clause := node.Body[i]
+		body, fall := clause.Body, false
+		if n := len(body); n > 0 {
+			if b, ok := body[n-1].(*ast.BranchStmt); ok && b.Token.Type == token.FALLTHROUGH {
+				body, fall = body[:n-1], true
+			}
+		}
+		// 每个 case 都是一个独立的作用域
+		r = evalBlockStmt(&ast.BlockStmt{Token: clause.Token, Stmts: body}, object.NewEnv(env))
+		if b, ok := r.(*object.Break); ok && (b.Label == "" || b.Label == label) {
+			return nil
+		}
+		if r != nil {
+			switch r.Type() {
+			case object.RETURN, object.ERROR, object.BREAK, object.CONTINUE:
+				return r
+			}
+		}
+		if !fall {
+			break
+		}
+	}
+	return r
+}
+
+// loopControl 处理一次循环体的执行结果,返回是否结束循环,以及结束时需要继续向上传递的
+// return、错误或属于外层循环的 break/continue
+func loopControl(r object.Object, label string) (done bool, result object.Object) {
+	switch r := r.(type) {
+	case *object.Break:
+		if r.Label == "" || r.Label == label {
+			return true, nil
+		}
+		return true, r
+	case *object.Continue:
+		if r.Label == "" || r.Label == label {
+			return false, nil
+		}
+		return true, r
+	case *object.Return, *object.Error:
+		return true, r
+	}
+	return false, nil
+}
+
 func evalPrefixExpr(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
 		return evalNotOperatorExpr(right)
 	case "-":
 		return evalSubOperatorExpr(right)
+	case "^":
+		return evalXorOperatorExpr(right)
 
 	default:
-		return &object.Error{Error: fmt.Sprintf("unknown prefix operator: " + operator + right.Type().String())}
+		return &object.Error{Error: fmt.Sprintf("unknown prefix operator: %s%s", operator, right.Type())}
 	}
 }
 
@@ -181,27 +474,272 @@ func evalNotOperatorExpr(right object.Object) object.Object {
 }
 
 func evalSubOperatorExpr(right object.Object) object.Object {
-	if right.Type() != object.INT {
+	switch v := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -v.Value}
+	case *object.Float:
+		return &object.Float{Value: -v.Value}
+	case *object.Complex:
+		return &object.Complex{Value: -v.Value}
+	default:
 		return &object.Error{Error: fmt.Sprintf("unknown sub operator: " + right.Type().String())}
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
+func evalXorOperatorExpr(right object.Object) object.Object {
+	v, ok := right.(*object.Integer)
+	if !ok {
+		return &object.Error{Error: fmt.Sprintf("unknown xor operator: " + right.Type().String())}
+	}
+	return &object.Integer{Value: ^v.Value}
+}
+
+// evalInfixExpr
+// 数值类型的提升规则: INT 与 INT 运算结果仍为 INT(除法截断取整),
+// 混合运算时按 INT -> FLOAT -> COMPLEX 的顺序提升为两者中较高的类型
 func evalInfixExpr(operator string, left, right object.Object) object.Object {
 	switch {
+	case operator == "&&" || operator == "||":
+		// 左操作数已在短路求值时判断,结果取决于右操作数
+		return &object.Boolean{Value: isTruthy(right)}
 	case left.Type() == object.INT && right.Type() == object.INT:
 		return evalIntegerInfixExpr(operator, left, right)
+	case isNumber(left) && isNumber(right) && (left.Type() == object.COMPLEX || right.Type() == object.COMPLEX):
+		return evalComplexInfixExpr(operator, toComplex(left), toComplex(right))
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpr(operator, toFloat(left), toFloat(right))
 	case left.Type() == object.String && right.Type() == object.String:
 		return evalStringerInfixExpr(operator, left, right)
+	case left.Type() == object.CHAR || right.Type() == object.CHAR:
+		return evalCharInfixExpr(operator, left, right)
 	case operator == "==" && left.Type() == right.Type():
 		return &object.Boolean{Value: left.Inspect() == right.Inspect()}
 	case operator == "!=" && left.Type() == right.Type():
 		return &object.Boolean{Value: left.Inspect() != right.Inspect()}
 	case left.Type() != right.Type():
-		return &object.Error{Error: fmt.Sprintf("type mismatch: " + left.Type().String() + " " + operator + " " + right.Type().String())}
+		return &object.Error{Error: fmt.Sprintf("type mismatch: %s %s %s", left.Type(), operator, right.Type())}
 	default:
-		return &object.Error{Error: fmt.Sprintf("unknown infix operator: " + left.Type().String() + " " + operator + " " + right.Type().String())}
+		return &object.Error{Error: fmt.Sprintf("unknown infix operator: %s %s %s", left.Type(), operator, right.Type())}
+	}
+}
+
+// evalConstStmt 依次对常量求值并绑定到当前作用域, iota 在表达式中为常量在分组中的序号
+func evalConstStmt(node *ast.ConstStmt, env *object.Env) object.Object {
+	for _, spec := range node.Specs {
+		if env.IsLocalConst(spec.Name.Value) {
+			return constRedeclared(spec.Name.Value)
+		}
+		if env.IsLocal(spec.Name.Value) {
+			return &object.Error{Error: spec.Name.Value + " redeclared in this block"}
+		}
+		scope := object.NewEnv(env)
+		scope.Set("iota", &object.Integer{Value: int64(spec.Iota)})
+		value := defaultEval(spec.Value, scope)
+		if isError(value) {
+			return value
+		}
+		if value == nil {
+			return noValue(spec.Value)
+		}
+		// 与 Go 相同,常量只能是标量,否则数组与 map 的元素仍然可以被修改
+		switch value.Type() {
+		case object.INT, object.FLOAT, object.COMPLEX, object.String, object.CHAR, object.BOOL:
+		default:
+			return &object.Error{Error: fmt.Sprintf("invalid constant type %s", value.Type())}
+		}
+		env.SetConst(spec.Name.Value, value)
+	}
+	return nil
+}
+
+// constRedeclared 常量不能在同一作用域中被重新声明,内层作用域可以遮蔽
+func constRedeclared(name string) object.Object {
+	return &object.Error{Error: "cannot redeclare constant " + name}
+}
+
+// evalAssignStmt
+// := 在当前作用域中定义变量; = 修改定义该变量的作用域中的绑定,而不是在当前作用域中遮蔽.
+// 与 Go 相同,先对左侧的索引表达式与右侧的全部表达式求值,再从左到右依次赋值,因此 a, b = b, a 可以交换两个值
+func evalAssignStmt(node *ast.AssignStmt, env *object.Env) object.Object {
+	switch node.TokenValue() {
+	case ":=":
+		// 与 Go 相同,左侧至少需要一个当前作用域中新的非空白标识符
+		declared := false
+		for _, target := range node.Targets {
+			name := target.(*ast.Identifier).Value
+			if env.IsLocalConst(name) {
+				return constRedeclared(name)
+			}
+			if name != "_" && !env.IsLocal(name) {
+				declared = true
+			}
+		}
+		if !declared {
+			return &object.Error{Error: "no new variables on left side of :="}
+		}
+		values := evalExpr(node.Values, env)
+		if len(values) == 1 && isError(values[0]) {
+			return values[0]
+		}
+		if err := checkValues(node.Values, values); err != nil {
+			return err
+		}
+		for i, target := range node.Targets {
+			// 空白标识符 _ 丢弃赋给它的值
+			if name := target.(*ast.Identifier).Value; name != "_" {
+				env.Set(name, values[i])
+			}
+		}
+
+	case "=":
+		targets := make([]assignTarget, 0, len(node.Targets))
+		for _, target := range node.Targets {
+			t, err := resolveTarget(target, env)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, t)
+		}
+		values := evalExpr(node.Values, env)
+		if len(values) == 1 && isError(values[0]) {
+			return values[0]
+		}
+		if err := checkValues(node.Values, values); err != nil {
+			return err
+		}
+		for i, t := range targets {
+			t.set(env, values[i])
+		}
+
+	default:
+		return evalUpdate(node.Targets[0], env, func(old object.Object) object.Object {
+			value := defaultEval(node.Values[0], env)
+			if isError(value) {
+				return value
+			}
+			if value == nil {
+				return noValue(node.Values[0])
+			}
+			// += 等复合赋值对应的二元运算符
+			return evalInfixExpr(strings.TrimSuffix(node.TokenValue(), "="), old, value)
+		})
+	}
+	return nil
+}
+
+// checkValues 检查赋值右侧的每个值都存在,调用没有返回值的函数得到的是 nil
+func checkValues(exprs []ast.Expr, values []object.Object) object.Object {
+	for i, value := range values {
+		if value != nil {
+			continue
+		}
+		if len(exprs) == len(values) {
+			return noValue(exprs[i])
+		}
+		return &object.Error{Error: "value used in assignment has no value"}
+	}
+	return nil
+}
+
+func noValue(expr ast.Expr) *object.Error {
+	return &object.Error{Error: fmt.Sprintf("%s (no value) used as value", expr)}
+}
+
+// evalUpdate 以 target 的当前值计算新值并写回,用于复合赋值与自增自减
+func evalUpdate(target ast.Expr, env *object.Env, update func(old object.Object) object.Object) object.Object {
+	// _ 没有值,不能参与复合赋值与自增自减
+	if ident, ok := target.(*ast.Identifier); ok && ident.Value == "_" {
+		return &object.Error{Error: "cannot use _ as value"}
+	}
+	t, err := resolveTarget(target, env)
+	if err != nil {
+		return err
+	}
+	value := update(t.get(env))
+	if isError(value) {
+		return value
+	}
+	t.set(env, value)
+	return nil
+}
+
+// assignTarget 赋值目标,name 与 left/index 二选一
+type assignTarget struct {
+	name  string        // 标识符
+	left  object.Object // 索引表达式中已求值的容器
+	index object.Object // 索引表达式中已求值的索引
+}
+
+// resolveTarget 对赋值目标中的索引表达式求值,并检查标识符已定义、容器支持按索引赋值.
+// 数组越界时报错而不是像读取一样返回 nil
+func resolveTarget(target ast.Expr, env *object.Env) (assignTarget, object.Object) {
+	switch t := target.(type) {
+	case *ast.Identifier:
+		// 空白标识符 _ 丢弃赋给它的值
+		if _, ok := env.Get(t.Value); !ok && t.Value != "_" {
+			return assignTarget{}, &object.Error{Error: "identifier not found: " + t.Value}
+		}
+		if env.IsConst(t.Value) {
+			return assignTarget{}, &object.Error{Error: "cannot assign to constant " + t.Value}
+		}
+		return assignTarget{name: t.Value}, nil
+
+	case *ast.IndexExpr:
+		left := defaultEval(t.Left, env)
+		if isError(left) {
+			return assignTarget{}, left
+		}
+		if left == nil {
+			return assignTarget{}, noValue(t.Left)
+		}
+		index := defaultEval(t.Index, env)
+		if isError(index) {
+			return assignTarget{}, index
+		}
+		if index == nil {
+			return assignTarget{}, noValue(t.Index)
+		}
+		switch l := left.(type) {
+		case *object.Array:
+			idx, ok := index.(*object.Integer)
+			if !ok {
+				return assignTarget{}, &object.Error{Error: fmt.Sprintf("index must be INT, got %s", index.Type())}
+			}
+			if idx.Value < 0 || idx.Value >= int64(len(l.Elements)) {
+				return assignTarget{}, &object.Error{Error: fmt.Sprintf("index out of range [%d] with length %d", idx.Value, len(l.Elements))}
+			}
+		case *object.Map:
+			if _, ok := index.(object.HashAble); !ok {
+				return assignTarget{}, &object.Error{Error: fmt.Sprintf("unhashable type: %s", index.Type())}
+			}
+		default:
+			return assignTarget{}, &object.Error{Error: fmt.Sprintf("index assignment not supported: %s", left.Type())}
+		}
+		return assignTarget{left: left, index: index}, nil
+
+	default:
+		return assignTarget{}, &object.Error{Error: fmt.Sprintf("cannot assign to %s", target)}
+	}
+}
+
+func (t assignTarget) get(env *object.Env) object.Object {
+	if t.left == nil {
+		v, _ := env.Get(t.name)
+		return v
+	}
+	return evalIndexExpr(t.left, t.index)
+}
+
+func (t assignTarget) set(env *object.Env, value object.Object) {
+	switch l := t.left.(type) {
+	case nil:
+		if t.name != "_" {
+			env.Assign(t.name, value)
+		}
+	case *object.Array:
+		l.Elements[t.index.(*object.Integer).Value] = value
+	case *object.Map:
+		l.Elements[t.index.(object.HashAble).MapKey()] = object.HashValue{Key: t.index, Value: value}
 	}
 }
 
@@ -216,18 +754,25 @@ func evalIdentifier(node *ast.Identifier, env *object.Env) object.Object {
 		return builtin
 	}
 
-	return val
+	return &object.Error{Error: "identifier not found: " + node.Value}
 }
 
 func evalIfExpr(node *ast.IfExpr, env *object.Env) object.Object {
+	if node.Init != nil {
+		env = object.NewEnv(env)
+		if r := defaultEval(node.Init, env); isError(r) {
+			return r
+		}
+	}
 	cond := Eval(node.Condition, env)
 	if isError(cond) {
 		return cond
 	}
+	// 与 for、switch 相同,分支中的声明只在分支内可见
 	if isTruthy(cond) {
-		return Eval(node.Consequence, env)
+		return Eval(node.Consequence, object.NewEnv(env))
 	} else if node.Alternative != nil {
-		return Eval(node.Alternative, env)
+		return Eval(node.Alternative, object.NewEnv(env))
 	} else {
 		return &object.Nil{}
 	}
@@ -254,6 +799,21 @@ func evalMapExpr(node *ast.Map, env *object.Env) object.Object {
 	return &object.Map{Elements: elements}
 }
 
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Env) object.Object {
+	var b strings.Builder
+	for _, part := range node.Parts {
+		v := defaultEval(part, env)
+		if isError(v) {
+			return v
+		}
+		if v == nil {
+			return noValue(part)
+		}
+		b.WriteString(v.Inspect())
+	}
+	return &object.Stringer{Value: b.String()}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch v := obj.(type) {
 	case *object.Nil:
@@ -268,6 +828,22 @@ func isTruthy(obj object.Object) bool {
 func evalExpr(args []ast.Expr, env *object.Env) []object.Object {
 	var r []object.Object
 	for _, arg := range args {
+		if spread, ok := arg.(*ast.SpreadExpr); ok {
+			// 展开数组的元素
+			eval := Eval(spread.Value, env)
+			if isError(eval) {
+				return []object.Object{eval}
+			}
+			if eval == nil {
+				return []object.Object{noValue(spread.Value)}
+			}
+			array, ok := eval.(*object.Array)
+			if !ok {
+				return []object.Object{&object.Error{Error: fmt.Sprintf("cannot spread %s", eval.Type())}}
+			}
+			r = append(r, array.Elements...)
+			continue
+		}
 		eval := Eval(arg, env)
 		if isError(eval) {
 			return []object.Object{eval}
@@ -277,12 +853,25 @@ func evalExpr(args []ast.Expr, env *object.Env) []object.Object {
 	return r
 }
 
-func callFunc(fn object.Object, args []object.Object) object.Object {
+// keywordArg 求值后的关键字参数
+type keywordArg struct {
+	name  string
+	value object.Object
+}
+
+func callFunc(fn object.Object, args []object.Object, keywords []keywordArg) object.Object {
 	switch _fn := fn.(type) {
 	case *object.Function:
-		eval := Eval(_fn.Body, extendFuncEnv(_fn, args))
+		env, err := extendFuncEnv(_fn, args, keywords)
+		if err != nil {
+			return err
+		}
+		eval := Eval(_fn.Body, env)
 		return unwrapReturnValue(eval)
 	case *object.Builtin:
+		if len(keywords) != 0 {
+			return &object.Error{Error: fmt.Sprintf("unknown keyword argument %s", keywords[0].name)}
+		}
 		return _fn.Fn(args...)
 	}
 	return &object.Error{Error: fmt.Sprintf("not a function")}
@@ -323,6 +912,22 @@ func translationObjToNode(obj object.Object) ast.Node {
 			},
 			Value: v.Value,
 		}
+	case *object.Float:
+		return ast.Float{
+			Token: &token.Token{
+				Type:  token.FLOAT,
+				Value: v.Inspect(),
+			},
+			Value: v.Value,
+		}
+	case *object.Char:
+		return ast.Char{
+			Token: &token.Token{
+				Type:  token.CHAR,
+				Value: v.Inspect(),
+			},
+			Value: v.Value,
+		}
 	case *object.Boolean:
 		t := &token.Token{
 			Type:  token.FALSE,
@@ -347,13 +952,73 @@ func translationObjToNode(obj object.Object) ast.Node {
 	}
 }
 
-func extendFuncEnv(fn *object.Function, args []object.Object) *object.Env {
-	env := object.NewEnv(fn.Env)
+// extendFuncEnv 按位置与关键字绑定实参,可变参数函数多余的位置实参收集为数组,
+// 缺少的实参使用默认值,默认值在函数作用域中按参数顺序求值,可以引用前面的参数
+func extendFuncEnv(fn *object.Function, args []object.Object, keywords []keywordArg) (*object.Env, *object.Error) {
+	fixed := len(fn.Parameters)
+	if fn.Variadic {
+		fixed--
+	}
+	required := fixed
+	for required > 0 && fn.Defaults != nil && fn.Defaults[required-1] != nil {
+		required--
+	}
 
-	for index, parameter := range fn.Parameters {
-		env.Set(parameter.Value, args[index])
+	switch {
+	case len(args) < required && len(keywords) == 0:
+		want := fmt.Sprintf("=%d", required)
+		if fn.Variadic || required != fixed {
+			want = fmt.Sprintf(">=%d", required)
+		}
+		return nil, &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want%s", len(args), want)}
+	case len(args) > fixed && !fn.Variadic:
+		want := fmt.Sprintf("=%d", fixed)
+		if required != fixed {
+			want = fmt.Sprintf("<=%d", fixed)
+		}
+		return nil, &object.Error{Error: fmt.Sprintf("wrong number of arguments. got=%d, want%s", len(args), want)}
 	}
-	return env
+
+	values := make([]object.Object, fixed)
+	copy(values, args)
+	for _, keyword := range keywords {
+		index := -1
+		for i, parameter := range fn.Parameters[:fixed] {
+			if parameter.Value == keyword.name {
+				index = i
+				break
+			}
+		}
+		switch {
+		case index < 0:
+			return nil, &object.Error{Error: fmt.Sprintf("unknown keyword argument %s", keyword.name)}
+		case values[index] != nil:
+			return nil, &object.Error{Error: fmt.Sprintf("multiple values for argument %s", keyword.name)}
+		}
+		values[index] = keyword.value
+	}
+
+	env := object.NewEnv(fn.Env)
+	for index, parameter := range fn.Parameters[:fixed] {
+		value := values[index]
+		if value == nil {
+			if fn.Defaults == nil || fn.Defaults[index] == nil {
+				return nil, &object.Error{Error: fmt.Sprintf("missing argument for parameter %s", parameter.Value)}
+			}
+			if value = Eval(fn.Defaults[index], env); isError(value) {
+				return nil, value.(*object.Error)
+			}
+		}
+		env.Set(parameter.Value, value)
+	}
+	if fn.Variadic {
+		var rest []object.Object
+		if len(args) > fixed {
+			rest = append(rest, args[fixed:]...)
+		}
+		env.Set(fn.Parameters[fixed].Value, &object.Array{Elements: rest})
+	}
+	return env, nil
 }
 
 func evalIntegerInfixExpr(operator string, left, right object.Object) object.Object {
@@ -365,19 +1030,162 @@ func evalIntegerInfixExpr(operator string, left, right object.Object) object.Obj
 		return &object.Integer{Value: l - r}
 	case "*":
 		return &object.Integer{Value: l * r}
+	case "/", "%":
+		if r == 0 {
+			return &object.Error{Error: "division by zero"}
+		}
+		if operator == "/" {
+			return &object.Integer{Value: l / r}
+		}
+		return &object.Integer{Value: l % r}
+	case "&":
+		return &object.Integer{Value: l & r}
+	case "|":
+		return &object.Integer{Value: l | r}
+	case "^":
+		return &object.Integer{Value: l ^ r}
+	case "&^":
+		return &object.Integer{Value: l &^ r}
+	case "<<", ">>":
+		if r < 0 {
+			return &object.Error{Error: fmt.Sprintf("negative shift count: %d", r)}
+		}
+		if operator == "<<" {
+			return &object.Integer{Value: l << uint64(r)}
+		}
+		return &object.Integer{Value: l >> uint64(r)}
+	case "<":
+		return &object.Boolean{Value: l < r}
+	case ">":
+		return &object.Boolean{Value: l > r}
+	case "<=":
+		return &object.Boolean{Value: l <= r}
+	case ">=":
+		return &object.Boolean{Value: l >= r}
+	case "==":
+		return &object.Boolean{Value: l == r}
+	case "!=":
+		return &object.Boolean{Value: l != r}
+	default:
+		return &object.Error{Error: fmt.Sprintf("unknown operator: %s%s%s", operator, left.Type(), right.Type())}
+	}
+}
+
+func evalFloatInfixExpr(operator string, l, r float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: l + r}
+	case "-":
+		return &object.Float{Value: l - r}
+	case "*":
+		return &object.Float{Value: l * r}
 	case "/":
-		return &object.Integer{Value: l / r}
+		return &object.Float{Value: l / r}
 	case "<":
 		return &object.Boolean{Value: l < r}
 	case ">":
 		return &object.Boolean{Value: l > r}
+	case "<=":
+		return &object.Boolean{Value: l <= r}
+	case ">=":
+		return &object.Boolean{Value: l >= r}
 	case "==":
 		return &object.Boolean{Value: l == r}
 	case "!=":
 		return &object.Boolean{Value: l != r}
 	default:
-		return &object.Error{Error: fmt.Sprintf("unknown operator: " + operator + left.Type().String() + right.Type().String())}
+		return &object.Error{Error: fmt.Sprintf("unknown operator: %s%s%s", operator, object.FLOAT, object.FLOAT)}
+	}
+}
+
+func evalComplexInfixExpr(operator string, l, r complex128) object.Object {
+	switch operator {
+	case "+":
+		return &object.Complex{Value: l + r}
+	case "-":
+		return &object.Complex{Value: l - r}
+	case "*":
+		return &object.Complex{Value: l * r}
+	case "/":
+		return &object.Complex{Value: l / r}
+	case "==":
+		return &object.Boolean{Value: l == r}
+	case "!=":
+		return &object.Boolean{Value: l != r}
+	default:
+		return &object.Error{Error: fmt.Sprintf("unknown operator: %s%s%s", operator, object.COMPLEX, object.COMPLEX)}
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Integer, *object.Float, *object.Complex:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(obj object.Object) float64 {
+	switch v := obj.(type) {
+	case *object.Integer:
+		return float64(v.Value)
+	case *object.Float:
+		return v.Value
+	default:
+		return 0
+	}
+}
+
+// evalCharInfixExpr
+// CHAR 与 CHAR 之间可以比较,相减得到 INT 距离;
+// CHAR 加减 INT 得到偏移后的 CHAR,如 'a' + 1 == 'b'
+func evalCharInfixExpr(operator string, left, right object.Object) object.Object {
+	switch l := left.(type) {
+	case *object.Char:
+		switch r := right.(type) {
+		case *object.Char:
+			switch operator {
+			case "-":
+				return &object.Integer{Value: int64(l.Value - r.Value)}
+			case "<":
+				return &object.Boolean{Value: l.Value < r.Value}
+			case ">":
+				return &object.Boolean{Value: l.Value > r.Value}
+			case "<=":
+				return &object.Boolean{Value: l.Value <= r.Value}
+			case ">=":
+				return &object.Boolean{Value: l.Value >= r.Value}
+			case "==":
+				return &object.Boolean{Value: l.Value == r.Value}
+			case "!=":
+				return &object.Boolean{Value: l.Value != r.Value}
+			}
+		case *object.Integer:
+			switch operator {
+			case "+":
+				return &object.Char{Value: l.Value + rune(r.Value)}
+			case "-":
+				return &object.Char{Value: l.Value - rune(r.Value)}
+			}
+		}
+	case *object.Integer:
+		if r, ok := right.(*object.Char); ok && operator == "+" {
+			return &object.Char{Value: rune(l.Value) + r.Value}
+		}
 	}
+
+	if left.Type() != right.Type() {
+		return &object.Error{Error: fmt.Sprintf("type mismatch: %s %s %s", left.Type(), operator, right.Type())}
+	}
+	return &object.Error{Error: fmt.Sprintf("unknown infix operator: %s %s %s", left.Type(), operator, right.Type())}
+}
+
+func toComplex(obj object.Object) complex128 {
+	if v, ok := obj.(*object.Complex); ok {
+		return v.Value
+	}
+	return complex(toFloat(obj), 0)
 }
 
 func evalStringerInfixExpr(operator string, left, right object.Object) object.Object {
@@ -385,8 +1193,20 @@ func evalStringerInfixExpr(operator string, left, right object.Object) object.Ob
 	switch operator {
 	case "+":
 		return &object.Stringer{Value: l + r}
+	case "<":
+		return &object.Boolean{Value: l < r}
+	case ">":
+		return &object.Boolean{Value: l > r}
+	case "<=":
+		return &object.Boolean{Value: l <= r}
+	case ">=":
+		return &object.Boolean{Value: l >= r}
+	case "==":
+		return &object.Boolean{Value: l == r}
+	case "!=":
+		return &object.Boolean{Value: l != r}
 	default:
-		return &object.Error{Error: fmt.Sprintf("unknown operator: " + operator + left.Type().String() + right.Type().String())}
+		return &object.Error{Error: fmt.Sprintf("unknown operator: %s%s%s", operator, left.Type(), right.Type())}
 	}
 }
 
@@ -394,6 +1214,8 @@ func evalIndexExpr(left object.Object, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY && index.Type() == object.INT:
 		return evalArrayIndexExpr(left, index)
+	case left.Type() == object.String && index.Type() == object.INT:
+		return evalStringerIndexExpr(left, index)
 	case left.Type() == object.MAP:
 		return evalMapIndexExpr(left, index)
 	default:
@@ -411,6 +1233,16 @@ func evalArrayIndexExpr(left object.Object, index object.Object) object.Object {
 	return array.Elements[idx]
 }
 
+// evalStringerIndexExpr 字符串按字符(rune)而不是字节索引,结果为 CHAR
+func evalStringerIndexExpr(left object.Object, index object.Object) object.Object {
+	runes := []rune(left.(*object.Stringer).Value)
+	idx := index.(*object.Integer).Value
+	if idx < 0 || idx >= int64(len(runes)) {
+		return &object.Nil{}
+	}
+	return &object.Char{Value: runes[idx]}
+}
+
 func evalMapIndexExpr(left object.Object, index object.Object) object.Object {
 	mp := left.(*object.Map)
 	key, ok := index.(object.HashAble)
@@ -29,12 +29,95 @@ func Test_evalIntegerExpr(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"6 &^ 3", 4},
+		{"1 << 10", 1024},
+		{"-8 >> 1", -4},
+		{"1 << 64", 0},
+		{"^0", -1},
+		{"^5 + 1", -5},
+		{"1 + 2 << 3 & 12 | 1", 1},
 	}
 	for _, tt := range tests {
 		testIntegerObj(t, testEval(tt.input), tt.expect)
 	}
 }
 
+func Test_evalOperatorError(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"1 << -1", "negative shift count: -1"},
+		{"1.5 % 1.0", "unknown operator: %FLOATFLOAT"},
+		{"^1.5", "unknown xor operator: FLOAT"},
+		{`"a" - "b"`, "unknown operator: -STRINGSTRING"},
+		{"true && (1 / 0)", "division by zero"},
+	}
+	for _, tt := range tests {
+		testError(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalFloatExpr(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{".5 + .25", 0.75},
+		{"1.5 * 2", 3.0},
+		{"2 * 1.5", 3.0},
+		{"7 / 2.0", 3.5},
+		{"7 / 2", 3},
+		{"1 - 0.5", 0.5},
+		{"1e3 + 1", 1001.0},
+		{"1.5 > 1", true},
+		{"1 < 1.5", true},
+		{"2 == 2.0", true},
+		{"2 != 2.0", false},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalComplexExpr(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"2i", 2i},
+		{"1 + 2i", 1 + 2i},
+		{"1.5 + 2i", 1.5 + 2i},
+		{"-(1 + 2i)", -1 - 2i},
+		{"(1 + 2i) * (3 - 1i)", 5 + 5i},
+		{"(4 + 2i) / 2", 2 + 1i},
+		{"1i * 1i", -1 + 0i},
+		{"complex(1, 2.5)", 1 + 2.5i},
+		{"1 + 2i == complex(1, 2)", true},
+		{"real(3 + 4i)", 3.0},
+		{"imag(3 + 4i)", 4.0},
+		{"imag(3)", 0.0},
+		{"abs(3 + 4i)", 5.0},
+		{"abs(-2.5)", 2.5},
+		{"abs(-2)", 2},
+		{"1i < 2i", "unknown operator: <COMPLEXCOMPLEX"},
+		{`abs("a")`, "argument to `abs` not supported, got STRING"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+	assert.Equal(t, "(1+2i)", testEval("1 + 2i").Inspect())
+}
+
 func Test_evalStringerExpr(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -60,6 +143,26 @@ func Test_evalStringExpr(t *testing.T) {
 	}
 }
 
+func Test_evalInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{`var name = "mini"; "hello ${name}!"`, "hello mini!"},
+		{`var items = [1, 2, 3]; "you have ${len(items)} items"`, "you have 3 items"},
+		{`"${1 + 1.5} ${'a'} ${true} ${[1, "b"]}"`, `2.5 a true [1, b]`},
+		{`var user = {"name": "bob"}; "hi ${user["name"]}, ${"${1}"}"`, "hi bob, 1"},
+		{`"cost: \${price} $5"`, "cost: ${price} $5"},
+	}
+	for _, tt := range tests {
+		testStringerObj(t, testEval(tt.input), tt.expect)
+	}
+
+	testError(t, testEval(`"${1 + true}"`), "type mismatch: INT + BOOL")
+	testError(t, testEval(`"hi ${nope}"`), "identifier not found: nope")
+	testError(t, testEval(`func f() {}; var x = "${f()}"`), "f() (no value) used as value")
+}
+
 func Test_evalBuiltinFuncExpr(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -69,12 +172,39 @@ func Test_evalBuiltinFuncExpr(t *testing.T) {
 		{`len(5)`, "argument to `len` not supported, got INT"},
 	}
 	for _, tt := range tests {
-		switch v := tt.expect.(type) {
-		case int:
-			testIntegerObj(t, testEval(tt.input), int64(v))
-		case string:
-			testError(t, testEval(tt.input), v)
-		}
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalCharExpr(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{`'a'`, 'a'},
+		{`'a' + 1`, 'b'},
+		{`1 + 'a'`, 'b'},
+		{`'z' - 1`, 'y'},
+		{`'z' - 'a'`, 25},
+		{`'a' < 'b'`, true},
+		{`'a' == 'a'`, true},
+		{`'a' != 'a'`, false},
+		{`"héllo"[1]`, 'é'},
+		{`"héllo"[5]`, nil},
+		{`len("héllo")`, 5},
+		{`int('A')`, 65},
+		{`int(2.9)`, 2},
+		{`int("0x10")`, 16},
+		{`char(65)`, 'A'},
+		{`char("世")`, '世'},
+		{`string('a') + "b"`, "ab"},
+		{`string(42)`, "42"},
+		{`{'a': 1}['a']`, 1},
+		{`'a' * 2`, "type mismatch: CHAR * INT"},
+		{`char("ab")`, `cannot convert "ab" to CHAR`},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
 	}
 }
 
@@ -115,15 +245,15 @@ func Test_evalArrayIndexExpr(t *testing.T) {
 			3,
 		},
 		{
-			"var a = [1, 2, 3] a[2]",
+			"var a = [1, 2, 3]; a[2]",
 			3,
 		},
 		{
-			"var a = [1, 2, 3] a[0] + a[1] + a[2]",
+			"var a = [1, 2, 3]; a[0] + a[1] + a[2]",
 			6,
 		},
 		{
-			"var a = [1, 2, 3] var i = a[0] a[i]",
+			"var a = [1, 2, 3]; var i = a[0]; a[i]",
 			2,
 		},
 		{
@@ -136,12 +266,7 @@ func Test_evalArrayIndexExpr(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		switch v := tt.expect.(type) {
-		case int:
-			testIntegerObj(t, testEval(tt.input), int64(v))
-		default:
-			testNilObj(t, testEval(tt.input))
-		}
+		testObj(t, testEval(tt.input), tt.expect)
 	}
 }
 
@@ -153,7 +278,7 @@ func Test_evalMapExpr(t *testing.T) {
 		"thr" + "ee": 6 / 2,
 		4: 4,
 		true: 5,
-		false: 6
+		false: 6,
 	}`
 	expr := testEval(input)
 	mp, ok := expr.(*object.Map)
@@ -209,12 +334,7 @@ func Test_evalMapIndexExpr(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		switch v := tt.expect.(type) {
-		case int:
-			testIntegerObj(t, testEval(tt.input), int64(v))
-		default:
-			testNilObj(t, testEval(tt.input))
-		}
+		testObj(t, testEval(tt.input), tt.expect)
 	}
 }
 
@@ -242,6 +362,23 @@ func Test_evalBooleanExpr(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 2", false},
+		{"1.5 >= 1.5", true},
+		{"'a' <= 'b'", true},
+		{`"abc" < "abd"`, true},
+		{`"b" >= "a"`, true},
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3 || false", true},
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{`false && (1 / 0) == 0`, false},
 	}
 	for _, tt := range tests {
 		testBooleanObj(t, testEval(tt.input), tt.expect)
@@ -277,15 +414,26 @@ func Test_evalIfExpr(t *testing.T) {
 		{"if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if 1 < 2 { 10 } else { 20 }", 10},
+		{"if 1 > 2 { 10 } else if 2 > 3 { 20 } else if (3 > 2) { 30 } else { 40 }", 30},
+		{"if 1 > 2 { 10 } else if 2 > 3 { 20 } else { 40 }", 40},
+		{"if 1 > 2 { 10 } else if 2 > 3 { 20 }", nil},
+		{"if x := 5; x > 10 { 1 } else if x > 3 { x * 2 } else { 3 }", 10},
+		{"if a := 1; a > 1 { 1 } else if b := a + 1; b == 2 { a + b * 10 }", 21},
+		{"v := 1; if v := 2; v == 2 { v = 3 }; v", 1},
+		{"func f() { return 7 }; if v := f(); v > 0 { return v }; 0", 7},
+		// 分支在各自的作用域中执行,可以遮蔽外层的变量,也可以修改外层的变量
+		{"x := 1; if true { x := 2; x = 3 }; x", 1},
+		{"x := 1; if false { } else { x := 2 }; x", 1},
+		{"x := 1; if true { x = 2 }; x", 2},
 	}
 	for _, tt := range tests {
-		switch vv := tt.expect.(type) {
-		case int:
-			testIntegerObj(t, testEval(tt.input), int64(vv))
-		default:
-			testNilObj(t, testEval(tt.input))
-		}
+		testObj(t, testEval(tt.input), tt.expect)
 	}
+
+	// 分支中的声明不会泄漏到外层作用域
+	testError(t, testEval("if true { y := 1 }; y"), "identifier not found: y")
+	testError(t, testEval("if false { } else { y := 1 }; y"), "identifier not found: y")
 }
 
 func Test_evalReturnExpr(t *testing.T) {
@@ -321,6 +469,9 @@ func Test_evalReturnExpr(t *testing.T) {
 	for _, tt := range tests {
 		testIntegerObj(t, testEval(tt.input), tt.expect)
 	}
+
+	testNilObj(t, testEval("func a() { return }; a()"))
+	testNilObj(t, testEval("func a() {\n\treturn\n}\na()"))
 }
 
 func Test_evalVarExpr(t *testing.T) {
@@ -328,10 +479,10 @@ func Test_evalVarExpr(t *testing.T) {
 		input  string
 		expect int64
 	}{
-		{"var a = 5 a", 5},
-		{"var a = 5 * 5 a", 25},
-		{"var a = 5 var b = a b", 5},
-		{"var a = 5 var b = a var c = a + b + 5 c", 15},
+		{"var a = 5; a", 5},
+		{"var a = 5 * 5; a", 25},
+		{"var a = 5; var b = a; b", 5},
+		{"var a = 5; var b = a; var c = a + b + 5; c", 15},
 	}
 	for _, tt := range tests {
 		testIntegerObj(t, testEval(tt.input), tt.expect)
@@ -354,11 +505,11 @@ func Test_evalCallExpr(t *testing.T) {
 		input  string
 		expect int64
 	}{
-		{"func a(x) { x }  a(5)", 5},
-		{"func a(x) { return x } a(5)", 5},
-		{"func double(x) { x * 2 } double(5)", 10},
-		{"func add(x, y) { x + y } add(5, 5)", 10},
-		{"func add(x, y) { x + y } add(5 + 5, add(5, 5))", 20},
+		{"func a(x) { x }; a(5)", 5},
+		{"func a(x) { return x }; a(5)", 5},
+		{"func double(x) { x * 2 }; double(5)", 10},
+		{"func add(x, y) { x + y }; add(5, 5)", 10},
+		{"func add(x, y) { x + y }; add(5 + 5, add(5, 5))", 20},
 		{"func add(x) { x }(5)", 5},
 	}
 	for _, tt := range tests {
@@ -366,6 +517,100 @@ func Test_evalCallExpr(t *testing.T) {
 	}
 }
 
+func Test_evalAnonymousFunc(t *testing.T) {
+	fn, ok := testEval("func(x, y) { x + y }").(*object.Function)
+	assert.Equal(t, true, ok)
+	assert.Nil(t, fn.Name)
+	assert.Equal(t, "func(x, y) {\n(x + y)\n}", fn.Inspect())
+
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"add := func(a, b) { a + b }; add(1, 2)", 3},
+		{"func(x) { x * 2 }(4)", 8},
+		{"func apply(f, x) { f(x) }; apply(func(x) { x + 1 }, 1)", 2},
+		{"fs := [func() { 1 }, func() { 2 }]; fs[0]() + fs[1]()", 3},
+		{`ops := {"add": func(a, b) { a + b }, "mul": func(a, b) { a * b }}; ops["add"](2, 3) * ops["mul"](2, 3)`, 30},
+		{"func adder(n) { func(x) { x + n } }; adder(2)(3)", 5},
+		{"func counter() { n := 0; return func() { n++; n } }; c := counter(); c(); c(); c()", 3},
+		{"func counter() { n := 0; return func() { n++; n } }; a := counter(); b := counter(); a(); a(); b()", 1},
+		// 匿名函数不会在当前作用域绑定名字
+		{"x := 1; func(x) { x }; x", 1},
+		{`f := func() {}; f`, "func() {\n\n}"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalVariadic(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"func sum(nums...) { s := 0; for _, n := range nums { s += n }; s }; sum(1, 2, 3)", 6},
+		{"func sum(nums...) { len(nums) }; sum()", 0},
+		{"func f(a, rest...) { a * 10 + len(rest) }; f(1)", 10},
+		{"func f(a, rest...) { a * 10 + len(rest) }; f(1, 2, 3)", 12},
+		{"func f(a, rest...) { rest[1] }; f(1, 2, 3)", 3},
+		{"func add(a, b) { a + b }; xs := [1, 2]; add(xs...)", 3},
+		{"func add(a, b) { a + b }; add(1, [2]...)", 3},
+		{"func sum(nums...) { s := 0; for _, n := range nums { s += n }; s }; xs := [1, 2]; sum(xs..., 3, xs...)", 9},
+		{"func sum(nums...) { len(nums) }; sum([]...)", 0},
+		// 收集的数组与展开的数组互不影响
+		{"func f(xs...) { xs[0] = 9 }; a := [1]; f(a...); a[0]", 1},
+		{"a := [1, 2]; b := [a..., 3, a...]; len(b) * 10 + b[3]", 51},
+		{"len([[1, 2]...])", 2},
+		{`len("abc", [1]...)`, "wrong number of arguments. got=2, want=1"},
+		{"func f(xs...) {}; f", "func f (xs...) {\n\n}"},
+		{"func add(a, b) { a + b }; add(1)", "wrong number of arguments. got=1, want=2"},
+		{"func add(a, b) { a + b }; add(1, 2, 3)", "wrong number of arguments. got=3, want=2"},
+		{"func add(a, b) { a + b }; add([1, 2, 3]...)", "wrong number of arguments. got=3, want=2"},
+		{"func f(a, b, rest...) { a }; f(1)", "wrong number of arguments. got=1, want>=2"},
+		{"func f(xs...) { 0 }; f(1...)", "cannot spread INT"},
+		{"[1, 2...]", "cannot spread INT"},
+		{"func f() {}; func g(a...) {}; g(f()...)", "f() (no value) used as value"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalDefaultsAndKeywords(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{`func connect(host, port = 8080) { "${host}:${port}" }; connect("db")`, "db:8080"},
+		{`func connect(host, port = 8080) { "${host}:${port}" }; connect("db", 5432)`, "db:5432"},
+		{`func connect(host, port = 8080) { "${host}:${port}" }; connect("db", port: 5432)`, "db:5432"},
+		{`func connect(host, port = 8080) { "${host}:${port}" }; connect(port: 1, host: "h")`, "h:1"},
+		{"func f(a = 1, b = 2, c = 3) { a * 100 + b * 10 + c }; f(c: 9)", 129},
+		{"func f(a = 1, b = 2, c = 3) { a * 100 + b * 10 + c }; f(4, c: 9)", 429},
+		// 默认值在调用时求值,可以引用前面的参数
+		{"func f(a, b = a * 2) { a + b }; f(3)", 9},
+		{"n := 1; func f(x = n) { x }; n = 5; f()", 5},
+		{"func f(a, b = 2, rest...) { a + b + len(rest) }; f(1)", 3},
+		{"func f(a, b = 2, rest...) { a + b + len(rest) }; f(1, 10, 0, 0)", 13},
+		{"func f(a, rest...) { a + len(rest) }; f(a: 5)", 5},
+		{"f := func(x = 3) { x * x }; f()", 9},
+		{"func f(a, b = 2) {}; f", "func f (a, b = 2) {\n\n}"},
+		{"func f(a, b) {}; f(1, c: 2)", "unknown keyword argument c"},
+		{"func f(a, rest...) {}; f(1, rest: 2)", "unknown keyword argument rest"},
+		{"func f(a, b) {}; f(1, a: 2)", "multiple values for argument a"},
+		{"func f(a, b) {}; f(b: 2)", "missing argument for parameter a"},
+		{"func f(a, b = 1) {}; f()", "wrong number of arguments. got=0, want>=1"},
+		{"func f(a, b = 1) {}; f(1, 2, 3)", "wrong number of arguments. got=3, want<=2"},
+		{"func f(a = 1 / 0) {}; f()", "division by zero"},
+		{"func f(a) {}; f(a: 1 / 0)", "division by zero"},
+		{`len(x: "a")`, "unknown keyword argument x"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
 func Test_evalEnv(t *testing.T) {
 	input := `
 var first = 10
@@ -382,6 +627,216 @@ add(20) + first + second
 	testIntegerObj(t, testEval(input), 70)
 }
 
+func Test_evalAssignStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"x := 5; x", 5},
+		{"x := 5; x, y := x + 1, 2; x + y", 8},
+		// := 左侧至少需要一个新的非空白标识符
+		{"x := 1; x := 2", "no new variables on left side of :="},
+		{"x, y := 1, 2; y, x := 3, 4", "no new variables on left side of :="},
+		{"_ := 5", "no new variables on left side of :="},
+		// 赋给 _ 的值被丢弃
+		{"_, x := 5, 6; x", 6},
+		{"_, x := 5, 6; _", "identifier not found: _"},
+		{"x := 1; _ = 2; _", "identifier not found: _"},
+		{"_++", "cannot use _ as value"},
+		{"_ += 1", "cannot use _ as value"},
+		{"_ += print", "cannot use _ as value"},
+		{"for _, v := range [1, 2] { _ }", "identifier not found: _"},
+		{"s := 0; for _, v := range [1, 2] { s += v }; s", 3},
+		{"a, b := 1, 2; a * 10 + b", 12},
+		{"a, b := 1, 2; a, b = b, a; a * 10 + b", 21},
+		{"a, b, c := 1, 2, 3; a, b, c = c, a, b; a * 100 + b * 10 + c", 312},
+		{"arr := [1, 2]; arr[0], arr[1] = arr[1], arr[0]; arr[0]", 2},
+		{"i := 0; arr := [5, 6]; i, arr[i] = 1, 9; arr[0] * 10 + i", 91},
+		{"x := 1; func f() { x := 2; x }; f() * 10 + x", 21},
+		{"x := 1; func f() { x = 2 }; f(); x", 2},
+		{"a, b = 1, 2", "identifier not found: a"},
+		{"a := 1; a, b = 1, 2; a", "identifier not found: b"},
+		{"var x = 1; x = 5; x", 5},
+		{`var x = 1; x = "s"; x`, "s"},
+		{"var x = 1; var y = 2; x = y + 1; x", 3},
+		{"var arr = [1, 2, 3]; arr[0] = 10; arr[0] + arr[2]", 13},
+		{`var m = {"a": 1}; m["a"] = 2; m["b"] = 3; m["a"] + m["b"] + len(m)`, 7},
+		{`var m = {}; m[1] = [0]; m[1][0] = 4; m[1][0]`, 4},
+		{"var n = 0; func set(x) { n = x }; set(7); n", 7},
+		{"var n = 0; if (true) { n = 1 }; n", 1},
+		{"x = 5", "identifier not found: x"},
+		{"var arr = [1]; arr[1] = 2", "index out of range [1] with length 1"},
+		{"var arr = [1]; arr[-1] = 2", "index out of range [-1] with length 1"},
+		{`var m = {}; m[[1]] = 2`, "unhashable type: ARRAY"},
+		{"var x = 1; x += 1; x", 2},
+		{"var x = 10; x -= 3; x *= 2; x /= 7; x", 2},
+		{"var x = 7; x %= 4; x", 3},
+		{"var x = 1; x <<= 2; x", 4},
+		{"var x = 16; x >>= 3; x", 2},
+		{"var x = 6; x &= 3; x", 2},
+		{"var x = 6; x |= 3; x", 7},
+		{"var x = 6; x ^= 3; x", 5},
+		{"var x = 6; x &^= 3; x", 4},
+		{"var x = 1.5; x += 1; x", 2.5},
+		{`var s = "a"; s += "b"; s`, "ab"},
+		{"var x = 1; x++; x++; x", 3},
+		{"var x = 1; x--; x", 0},
+		{"var arr = [1, 2, 3]; var i = 1; arr[i] *= 3; arr[1]", 6},
+		{"var arr = [1, 2, 3]; arr[0]++; arr[0]", 2},
+		{`var m = {"k": 5}; m["k"] -= 1; m["k"]`, 4},
+		{`var m = {"k": [1]}; m["k"][0] += 1; m["k"][0]`, 2},
+		// 修改外层作用域中的绑定,而不是在函数作用域中遮蔽
+		{"var n = 0; func inc() { n++ }; inc(); inc(); n", 2},
+		{"var n = 0; func add(x) { n += x }; add(5); add(6); n", 11},
+		{"var n = 0; func f(n) { n += 1; n }; f(5) + n", 6},
+		{"y += 1", "identifier not found: y"},
+		{"var x = 1; x /= 0", "division by zero"},
+		{`var x = "a"; x++`, "type mismatch: STRING + INT"},
+		{"var arr = [1]; arr[3] += 1", "index out of range [3] with length 1"},
+		{`var s = "abc"; s[0] += 1`, "index assignment not supported: STRING"},
+		{"var x = 1; x += nope", "identifier not found: nope"},
+		{"var arr = [1]; arr[nope] = 1", "identifier not found: nope"},
+		{"var m = {}; m[nope] = 1", "identifier not found: nope"},
+		{"var a = [1]; a[0] = nope; a", "identifier not found: nope"},
+		// 没有返回值的调用不能作为值使用
+		{"func f() {}; var x = f()", "f() (no value) used as value"},
+		{"func f() {}; x := f()", "f() (no value) used as value"},
+		{"func f() {}; var x = 1; x += f()", "f() (no value) used as value"},
+		{"func f() {}; var a = [1]; a[f()] = 1", "f() (no value) used as value"},
+		{"func f() {}; var a = [1]; a[0] = f()", "f() (no value) used as value"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalConstStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"const A = 5; A", 5},
+		{"const A = 2; const B = A * 3; B", 6},
+		{"const (A = iota; B; C); A * 100 + B * 10 + C", 12},
+		{"const (\n\tKB = 1 << (10 * (iota + 1))\n\tMB\n\tGB\n)\nGB / MB", 1024},
+		{"const (A = 7; B; C = iota; D); A + B + C * 10 + D", 37},
+		{`const Name = "cfg"; Name`, "cfg"},
+		// 内层作用域可以遮蔽外层的常量
+		{"const A = 1; func f() { A := 2; A }; f() * 10 + A", 21},
+		{"const A = 1; func f(A) { A = 3; A }; f(2)", 3},
+		{"const A = 1; for A := 0; A < 2; A++ {}; A", 1},
+		// 常量只能是标量
+		{"const A = [1, 2]", "invalid constant type ARRAY"},
+		{`const M = {"a": 1}`, "invalid constant type MAP"},
+		{"func f() {}; const F = f", "invalid constant type FUNCTION"},
+		{"var A = 1; const A = 2", "A redeclared in this block"},
+		{"A := 1; const (B = 1; A = 2)", "A redeclared in this block"},
+		{"var A = 1; func f() { const A = 2; A }; f() * 10 + A", 21},
+		// 每次迭代在新的作用域中执行循环体
+		{"n := 0; for i := 0; i < 3; i++ { const C = 2; n += C }; n", 6},
+		{"n := 0; for _, v := range [1, 2, 3] { const C = 10; n += v * C }; n", 60},
+		{"fs := [0, 0]; for i, v := range [1, 2] { fs[i] = func() { v } }; fs[0]() * 10 + fs[1]()", 12},
+		{"const A = 1; A = 2", "cannot assign to constant A"},
+		{"const A = 1; A += 2", "cannot assign to constant A"},
+		{"const A = 1; A++", "cannot assign to constant A"},
+		{"const A = 1; var b = 0; A, b = 2, 3", "cannot assign to constant A"},
+		{"const A = 1; func f() { A = 2 }; f()", "cannot assign to constant A"},
+		{"const A = 1; for A = range [1] {}", "cannot assign to constant A"},
+		{"const A = 1; A := 2", "cannot redeclare constant A"},
+		{"const A = 1; var b = 0; b, A := 1, 2", "cannot redeclare constant A"},
+		{"const A = 1; var A = 2", "cannot redeclare constant A"},
+		{"const A = 1; const A = 2", "cannot redeclare constant A"},
+		{"const (A = 1; A = 2)", "cannot redeclare constant A"},
+		{"const A = 1; func A() {}", "cannot redeclare constant A"},
+		{"const A = 1 / 0", "division by zero"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalForStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"sum := 0; for i := 0; i < 5; i++ { sum += i }; sum", 10},
+		{"i := 0; for i < 5 { i++ }; i", 5},
+		{"i := 0; for { i++; if (i == 3) { break } }; i", 3},
+		{"i := 0; for ; i < 4; { i += 2 }; i", 4},
+		{"sum := 0; for i := 0; i < 6; i++ { if (i % 2 == 0) { continue }; sum += i }; sum", 9},
+		// 循环变量的作用域仅限于循环
+		{"i := 10; for i := 0; i < 3; i++ {}; i", 10},
+		{"x := 0; for i := 0; i < 3; i++ { x = i }; x", 2},
+		{"n := 0; for i := 0; i < 3; i++ { for j := 0; j < 3; j++ { if (j == 1) { break }; n++ } }; n", 3},
+		{"n := 0; outer: for i := 0; i < 3; i++ { for j := 0; j < 3; j++ { if (j == 1) { continue outer }; n++ } }; n", 3},
+		{"n := 0; outer: for i := 0; i < 3; i++ { for { n++; break outer } }; n", 1},
+		{"sum := 0; for i, v := range [1, 2, 3] { sum += i * 10 + v }; sum", 36},
+		{"n := 0; for range [1, 2, 3] { n++ }; n", 3},
+		{"n := 0; for i := range [4, 5] { n += i }; n", 1},
+		{"sum := 0; for _, v := range [1, 2, 3] { sum += v }; sum", 6},
+		{`s := ""; for _, c := range "héllo" { s = "${c}" + s }; s`, "olléh"},
+		{`n := 0; for i := range "héllo" { n = i }; n`, 4},
+		{`sum := 0; for k, v := range {1: 10, 2: 20} { sum += k + v }; sum`, 33},
+		{"var k = 0; var v = 0; for k, v = range [7, 8] {}; k * 10 + v", 18},
+		{"arr := [0, 0]; for i := range arr { arr[i] = i + 1 }; arr[0] + arr[1]", 3},
+		{"func f() { for i := 0; ; i++ { if (i == 4) { return i } } }; f()", 4},
+		{"func f() { for _, v := range [1, 2, 3] { if (v == 2) { return v * 10 } }; 0 }; f()", 20},
+		{"for _, v := range 1 {}", "cannot range over INT"},
+		{"for i := 0; i < 3; i++ { x = 1 }", "identifier not found: x"},
+		{"for i := 0; i < 3; i++ { 1 / 0 }", "division by zero"},
+		{"for nope {}", "identifier not found: nope"},
+		{"for range nope {}", "identifier not found: nope"},
+		{"for k := range nope {}", "identifier not found: nope"},
+		{"for k, v := range nope {}", "identifier not found: nope"},
+		{"func f() {}; for f() { break }", "f() (no value) used as value"},
+		{"func f() {}; for range f() {}", "f() (no value) used as value"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
+func Test_evalSwitchStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect interface{}
+	}{
+		{"x := 2; r := 0; switch x { case 1: r = 10; case 2, 3: r = 20; default: r = 30 }; r", 20},
+		{"x := 3; r := 0; switch x { case 1: r = 10; case 2, 3: r = 20; default: r = 30 }; r", 20},
+		{"x := 9; r := 0; switch x { case 1: r = 10; case 2, 3: r = 20; default: r = 30 }; r", 30},
+		// default 可以出现在任意位置,只在没有 case 匹配时执行
+		{"x := 1; r := 0; switch x { default: r = 30; case 1: r = 10 }; r", 10},
+		{"r := 0; switch 5 { case 1: r = 10 }; r", 0},
+		{"x := 5; r := 0; switch { case x > 3: r = 1; case x > 1: r = 2 }; r", 1},
+		{"x := 2; r := 0; switch { case x > 3: r = 1; case x > 1: r = 2 }; r", 2},
+		{`r := ""; switch s := "b"; s { case "a": r = "A"; case "b": r = "B" }; r`, "B"},
+		{"r := 0; switch x := 4; { case x % 2 == 0: r = x }; r", 4},
+		// fallthrough 不再比较下一个 case 的表达式
+		{"r := 0; switch 1 { case 1: r += 1; fallthrough; case 2: r += 10; fallthrough; default: r += 100; case 3: r += 1000 }; r", 111},
+		{"r := 0; switch 2 { case 1: r += 1; fallthrough; case 2: r += 10 }; r", 10},
+		{"r := 0; switch 1 { case 1: if (true) { break }; r = 1 }; r", 0},
+		{"n := 0; for i := 0; i < 5; i++ { switch { case i == 3: break; default: n++ } }; n", 4},
+		{"n := 0; for i := 0; i < 5; i++ { switch { case i % 2 == 0: continue }; n++ }; n", 2},
+		{"n := 0; outer: for i := 0; i < 5; i++ { switch { case i == 2: break outer }; n++ }; n", 2},
+		{"r := 0; L: switch 1 { case 1: for { break L }; r = 1 }; r", 0},
+		{`func route(x) { switch x { case "a": return 1; case "b": return 2 }; 0 }; route("b") * 10 + route("c")`, 20},
+		{`func route(x) { switch x { case "a": "A"; default: "?" } }; route("a")`, "A"},
+		{"x := 1; switch x := 2; x { case 2: x = 3 }; x", 1},
+		{"x := 5; switch 1 { case 1: x := 1; fallthrough; case 2: x = x + 1 }; x", 6},
+		{`switch 1 { case "a": }`, "type mismatch: INT == STRING"},
+		{"switch 1 / 0 { }", "division by zero"},
+		{"switch 1 { case 1: 1 / 0 }", "division by zero"},
+		{"switch nope { case 1: }", "identifier not found: nope"},
+		{"switch 1 { case nope: }", "identifier not found: nope"},
+		{"func f() {}; switch f() { case 1: }", "f() (no value) used as value"},
+		{"func f() {}; switch 1 { case 2, f(): }", "f() (no value) used as value"},
+	}
+	for _, tt := range tests {
+		testObj(t, testEval(tt.input), tt.expect)
+	}
+}
+
 func Test_evalQuote(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -400,6 +855,14 @@ func Test_evalQuote(t *testing.T) {
 			`quote(foobar + barfoo)`,
 			`(foobar + barfoo)`,
 		},
+		{
+			`quote("\${x}")`,
+			`"\${x}"`,
+		},
+		{
+			`quote("\${x} ${y}")`,
+			`"\${x} ${y}"`,
+		},
 	}
 	for _, tt := range tests {
 		q, ok := testEval(tt.input).(*object.Quote)
@@ -442,7 +905,7 @@ func Test_evalUnQuote(t *testing.T) {
 			`(4 + 4)`,
 		},
 		{
-			`var quotedInfixExpression = quote(4 + 4) quote(unquote(4 + 4) + unquote(quotedInfixExpression))`,
+			`var quotedInfixExpression = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfixExpression))`,
 			`(8 + (4 + 4))`,
 		},
 	}
@@ -527,12 +990,62 @@ func testEval(input string) object.Object {
 	return Eval(p.ParseProgram(), object.NewEnv(nil))
 }
 
+// testObj 按期望值的类型检查求值结果,期望值为字符串时比较错误信息、字符串的值或其他对象的 Inspect 输出,
+// 期望值为 nil 时结果应为 Nil
+func testObj(t *testing.T, obj object.Object, expect interface{}) {
+	switch v := expect.(type) {
+	case int:
+		testIntegerObj(t, obj, int64(v))
+	case float64:
+		testFloatObj(t, obj, v)
+	case complex128:
+		testComplexObj(t, obj, v)
+	case rune:
+		testCharObj(t, obj, v)
+	case bool:
+		testBooleanObj(t, obj, v)
+	case string:
+		switch obj.(type) {
+		case *object.Error:
+			testError(t, obj, v)
+		case *object.Stringer:
+			testStringerObj(t, obj, v)
+		default:
+			if assert.NotNil(t, obj) {
+				assert.Equal(t, v, obj.Inspect())
+			}
+		}
+	case nil:
+		testNilObj(t, obj)
+	default:
+		t.Fatalf("unsupported expect type %T", expect)
+	}
+}
+
 func testIntegerObj(t *testing.T, obj object.Object, expect int64) {
 	result, ok := obj.(*object.Integer)
 	assert.Equal(t, ok, true)
 	assert.Equal(t, result.Value, expect)
 }
 
+func testFloatObj(t *testing.T, obj object.Object, expect float64) {
+	result, ok := obj.(*object.Float)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, result.Value, expect)
+}
+
+func testComplexObj(t *testing.T, obj object.Object, expect complex128) {
+	result, ok := obj.(*object.Complex)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, result.Value, expect)
+}
+
+func testCharObj(t *testing.T, obj object.Object, expect rune) {
+	result, ok := obj.(*object.Char)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, result.Value, expect)
+}
+
 func testError(t *testing.T, obj object.Object, expect string) {
 	result, ok := obj.(*object.Error)
 	assert.Equal(t, ok, true)
@@ -1,15 +1,101 @@
 package lexer
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/songzhibin97/mini-interpreter/token"
 )
 
+// bufferSize 从 io.Reader 读取时的缓冲区大小
+const bufferSize = 4096
+
 type Lexer struct {
-	pos   int    // 解析器当前解析到的位置
-	ln    int    // input 长度
-	input []rune // 解析器需要解析的字符串
+	pos    int           // 解析器当前解析到的位置
+	src    *bufio.Reader // 输入源,按需增量读取
+	ahead  []rune        // 已从输入源读取但尚未消费的字符,仅供 peek 使用,长度不超过最大偏移量
+	eof    bool          // 输入源已读完或读取出错
+	file   *token.File   // 源文件信息,记录每一行的起始位置
+	mode   Mode          // 解析模式
+	errors []Error       // 解析过程中遇到的词法错误
+
+	insertSemi bool  // 遇到换行时是否需要自动插入分号
+	semiInLine bool  // ScanComments 模式下,上一个块注释中包含换行
+	interp     []int // 尚未结束的字符串插值 ${...} 内部未闭合的 { 数量,支持嵌套
+	depth      int   // 未闭合的 ( [ { 数量
+	multiline  bool  // 正在读取或输入结束于可以跨越多行的原始字符串、块注释
+}
+
+// Mode
+// @Description: 控制词法解析器行为的标志位
+type Mode uint
+
+const (
+	ScanComments Mode = 1 << iota // 输出 COMMENT 词法单元,而不是跳过注释
+)
+
+// Error
+// @Description: 词法错误,包含出错位置与描述
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+func (e Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// Option
+// @Description: 词法解析器的可选配置
+type Option func(l *Lexer)
+
+// WithFile
+// @Description: 指定源文件,词法单元的位置基于该文件计算
+// @param file: 通常由 token.FileSet.AddFile 创建
+// @return Option
+func WithFile(file *token.File) Option {
+	return func(l *Lexer) {
+		l.file = file
+	}
+}
+
+// WithMode
+// @Description: 指定解析模式,如 ScanComments
+// @param mode:
+// @return Option
+func WithMode(mode Mode) Option {
+	return func(l *Lexer) {
+		l.mode = mode
+	}
+}
+
+// Errors
+// @Description: 获取目前为止遇到的词法错误
+// @receiver l
+// @return []Error
+func (l *Lexer) Errors() []Error {
+	return l.errors
+}
+
+// error
+// @Description: 记录一条词法错误
+// @receiver l
+// @param offset: 出错位置
+// @param msg: 错误描述
+func (l *Lexer) error(offset int, msg string) {
+	l.errors = append(l.errors, Error{Pos: l.file.Position(l.file.Pos(offset)), Msg: msg})
+}
+
+// File
+// @Description: 获取当前解析的源文件,用于将 token.Pos 换算为行列号
+// @receiver l
+// @return *token.File
+func (l *Lexer) File() *token.File {
+	return l.file
 }
 
 // next
@@ -17,13 +103,18 @@ type Lexer struct {
 // @receiver l
 // @return rune
 func (l *Lexer) next() rune {
-	if l.pos >= l.ln {
+	if !l.fill(1) {
 		// 0 => EOF
 		return 0
 	}
 
-	ret := l.input[l.pos]
+	ret := l.ahead[0]
+	// 原地前移,复用 ahead 的底层数组
+	l.ahead = l.ahead[:copy(l.ahead, l.ahead[1:])]
 	l.pos++
+	if ret == '\n' {
+		l.file.AddLine(l.pos)
+	}
 	return ret
 }
 
@@ -33,10 +124,30 @@ func (l *Lexer) next() rune {
 // @param offset: 偏移量
 // @return rune
 func (l *Lexer) peek(offset int) rune {
-	if l.pos+offset >= l.ln {
+	if !l.fill(offset + 1) {
 		return 0
 	}
-	return l.input[l.pos+offset]
+	return l.ahead[offset]
+}
+
+// fill
+// @Description: 从输入源读取字符,直到 ahead 中至少有 n 个字符
+// @receiver l
+// @param n:
+// @return bool: 输入源中是否还有足够的字符
+func (l *Lexer) fill(n int) bool {
+	for len(l.ahead) < n && !l.eof {
+		v, _, err := l.src.ReadRune()
+		if err != nil {
+			if err != io.EOF {
+				l.error(l.pos+len(l.ahead), "read error: "+err.Error())
+			}
+			l.eof = true
+			break
+		}
+		l.ahead = append(l.ahead, v)
+	}
+	return len(l.ahead) >= n
 }
 
 func isLetter(v rune, index int) bool {
@@ -44,72 +155,524 @@ func isLetter(v rune, index int) bool {
 }
 
 func isDigit(v rune) bool {
-	return unicode.IsDigit(v)
+	return '0' <= v && v <= '9'
+}
+
+func isHex(v rune) bool {
+	return '0' <= v && v <= '9' || 'a' <= lower(v) && lower(v) <= 'f'
+}
+
+func lower(v rune) rune {
+	return ('a' - 'A') | v
 }
 
 func (l *Lexer) letter() string {
-	pos := l.pos
-	for ; l.pos < l.ln; l.pos++ {
-		v := l.input[l.pos]
-		if !isLetter(v, l.pos-pos+1) {
-			break
+	var b strings.Builder
+	for index := 1; isLetter(l.peek(0), index); index++ {
+		b.WriteRune(l.next())
+	}
+	return b.String()
+}
+
+// digits
+// @Description: 读取指定进制的数字序列,允许使用 '_' 分隔
+// @receiver l
+// @param base: 进制,为 2 8 10 16 之一
+// @return string
+// @return invalid: 第一个超出进制范围的数字在返回值中的下标,不存在时为 -1
+func (l *Lexer) digits(base int) (string, int) {
+	var b strings.Builder
+	invalid := -1
+	if base <= 10 {
+		max := rune('0' + base)
+		for v := l.peek(0); isDigit(v) || v == '_'; v = l.peek(0) {
+			if v != '_' && v >= max && invalid < 0 {
+				invalid = b.Len()
+			}
+			b.WriteRune(l.next())
+		}
+	} else {
+		for v := l.peek(0); isHex(v) || v == '_'; v = l.peek(0) {
+			b.WriteRune(l.next())
 		}
 	}
-	ret := l.input[pos:l.pos]
-	return string(ret)
+	return b.String(), invalid
 }
 
-func (l *Lexer) digit() string {
-	pos := l.pos
-	for ; l.pos < l.ln; l.pos++ {
-		v := l.input[l.pos]
-		if !isDigit(v) {
-			break
+func litName(prefix rune) string {
+	switch prefix {
+	case 'x':
+		return "hexadecimal literal"
+	case 'o', '0':
+		return "octal literal"
+	case 'b':
+		return "binary literal"
+	}
+	return "decimal literal"
+}
+
+// invalidSep returns the index of the first invalid separator in x, or -1.
+func invalidSep(x string) int {
+	x1 := ' ' // prefix char, we only care if it's 'x'
+	d := '.'  // digit, one of '_', '0' (a digit), or '.' (anything else)
+	i := 0
+
+	// a prefix counts as a digit
+	if len(x) >= 2 && x[0] == '0' {
+		x1 = lower(rune(x[1]))
+		if x1 == 'x' || x1 == 'o' || x1 == 'b' {
+			d = '0'
+			i = 2
+		}
+	}
+
+	// mantissa and exponent
+	for ; i < len(x); i++ {
+		p := d // previous digit
+		d = rune(x[i])
+		switch {
+		case d == '_':
+			if p != '0' {
+				return i
+			}
+		case isDigit(d) || x1 == 'x' && isHex(d):
+			d = '0'
+		default:
+			if p == '_' {
+				return i - 1
+			}
+			d = '.'
+		}
+	}
+	if d == '_' {
+		return len(x) - 1
+	}
+
+	return -1
+}
+
+// number
+// @Description: 读取数字字面量. 整数支持 0x 0o 0b 前缀、0 开头的八进制与 '_' 分隔符,
+// 即 strconv.ParseInt(v, 0, 64) 接受的全部形式; 小数支持 1.5 .5 1. 与指数 1e-9 形式;
+// 以上任意形式后跟 'i' 即为虚数
+// @receiver l
+// @param offset: 字面量起始位置
+// @param first: 已读取的首字符,为数字或 '.'
+// @return token.Type: INT FLOAT 或 IMAG
+// @return string
+func (l *Lexer) number(offset int, first rune) (token.Type, string) {
+	var b strings.Builder
+	b.WriteRune(first)
+	tp := token.INT
+	prefix := rune(0)
+	invalid := -1 // 非法数字相对字面量起始位置的偏移
+
+	if first == '.' {
+		tp = token.FLOAT
+	} else {
+		base := 10
+		if first == '0' {
+			switch lower(l.peek(0)) {
+			case 'x':
+				base, prefix = 16, 'x'
+			case 'o':
+				base, prefix = 8, 'o'
+			case 'b':
+				base, prefix = 2, 'b'
+			default:
+				base, prefix = 8, '0'
+			}
+			if prefix != '0' {
+				b.WriteRune(l.next())
+			}
+		}
+
+		start := b.Len()
+		digits, i := l.digits(base)
+		if i >= 0 {
+			invalid = start + i
+		}
+		b.WriteString(digits)
+		if prefix != 0 && prefix != '0' && strings.Trim(digits, "_") == "" {
+			l.error(offset, litName(prefix)+" has no digits")
+		}
+
+		// 1... 中的 ... 保留给 ELLIPSIS
+		if (prefix == 0 || prefix == '0') && l.peek(0) == '.' && l.peek(1) != '.' {
+			b.WriteRune(l.next())
+			tp = token.FLOAT
+		}
+	}
+	if tp == token.FLOAT {
+		digits, _ := l.digits(10)
+		b.WriteString(digits)
+	}
+
+	if v := lower(l.peek(0)); v == 'e' && (prefix == 0 || prefix == '0') {
+		tp = token.FLOAT
+		b.WriteRune(l.next())
+		if v = l.peek(0); v == '+' || v == '-' {
+			b.WriteRune(l.next())
+		}
+		digits, _ := l.digits(10)
+		if digits == "" {
+			l.error(offset, "exponent has no digits")
+		}
+		b.WriteString(digits)
+	}
+
+	// 虚数后缀,如 2i 1.5i 1e3i
+	if l.peek(0) == 'i' {
+		tp = token.IMAG
+		b.WriteRune(l.next())
+	}
+
+	v := b.String()
+	// 0 开头的小数或虚数如 09.5 09i 为合法的十进制字面量
+	if tp == token.INT && invalid >= 0 {
+		l.error(offset+invalid, fmt.Sprintf("invalid digit %q in %s", v[invalid], litName(prefix)))
+	}
+	if i := invalidSep(v); i >= 0 {
+		l.error(offset+i, "'_' must separate successive digits")
+	}
+	return tp, v
+}
+
+func digitVal(v rune) int {
+	switch {
+	case '0' <= v && v <= '9':
+		return int(v - '0')
+	case 'a' <= v && v <= 'f':
+		return int(v - 'a' + 10)
+	case 'A' <= v && v <= 'F':
+		return int(v - 'A' + 10)
+	}
+	return 16 // larger than any legal digit val
+}
+
+// escape
+// @Description: 解析转义序列,调用前 '\' 已被读取. 支持 Go 中的全部转义形式
+// @receiver l
+// @param quote: 当前字面量的引号,只有该引号可以被转义
+// @return value: 转义后的值
+// @return isByte: \xNN 与八进制转义表示单个字节而不是 Unicode 字符
+// @return ok: 转义序列是否合法,不合法时已记录错误
+func (l *Lexer) escape(quote rune) (value rune, isByte bool, ok bool) {
+	offset := l.pos - 1
+	// 双引号字符串中 \$ 表示字面量 $,用于避免 ${ 开始插值
+	if quote == '"' && l.peek(0) == '$' {
+		l.next()
+		return '$', false, true
+	}
+	var n int
+	var base, max uint32
+	switch v := l.peek(0); v {
+	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', quote:
+		l.next()
+		switch v {
+		case 'a':
+			return '\a', false, true
+		case 'b':
+			return '\b', false, true
+		case 'f':
+			return '\f', false, true
+		case 'n':
+			return '\n', false, true
+		case 'r':
+			return '\r', false, true
+		case 't':
+			return '\t', false, true
+		case 'v':
+			return '\v', false, true
+		}
+		return v, false, true
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		n, base, max, isByte = 3, 8, 255, true
+	case 'x':
+		l.next()
+		n, base, max, isByte = 2, 16, 255, true
+	case 'u':
+		l.next()
+		n, base, max = 4, 16, unicode.MaxRune
+	case 'U':
+		l.next()
+		n, base, max = 8, 16, unicode.MaxRune
+	default:
+		if v == 0 || v == '\n' {
+			l.error(offset, "escape sequence not terminated")
+		} else {
+			l.next()
+			l.error(offset, "unknown escape sequence")
+		}
+		return 0, false, false
+	}
+
+	var x uint32
+	for ; n > 0; n-- {
+		v := l.peek(0)
+		d := uint32(digitVal(v))
+		if d >= base {
+			if v == 0 || v == '\n' {
+				l.error(offset, "escape sequence not terminated")
+			} else {
+				l.error(l.pos, fmt.Sprintf("illegal character %#U in escape sequence", v))
+			}
+			return 0, false, false
 		}
+		x = x*base + d
+		l.next()
 	}
-	ret := l.input[pos:l.pos]
-	return string(ret)
+
+	if x > max || 0xD800 <= x && x < 0xE000 {
+		l.error(offset, "escape sequence is invalid Unicode code point")
+		return 0, false, false
+	}
+	return rune(x), isByte, true
 }
 
-func (l *Lexer) string() string {
-	pos := l.pos
-	for ; l.pos < l.ln; l.pos++ {
-		v := l.input[l.pos]
-		if v == '"' {
+// string
+// @Description: 读取双引号字符串并处理转义,调用前起始的 '"' 或插值结束的 '}' 已被读取
+// @receiver l
+// @param offset: 字面量起始位置
+// @return string: 转义后的字符串内容
+// @return bool: 是否遇到 ${ 开始插值
+func (l *Lexer) string(offset int) (string, bool) {
+	var b strings.Builder
+	for {
+		v := l.peek(0)
+		if v == '\n' || v == 0 {
+			l.error(offset, "string literal not terminated")
+			return b.String(), false
+		}
+		l.next()
+		switch v {
+		case '"':
+			return b.String(), false
+		case '$':
+			if l.peek(0) != '{' {
+				b.WriteRune(v)
+				continue
+			}
+			l.next()
+			l.interp = append(l.interp, 0)
+			return b.String(), true
+		case '\\':
+			value, isByte, ok := l.escape('"')
+			switch {
+			case !ok:
+			case isByte:
+				b.WriteByte(byte(value))
+			default:
+				b.WriteRune(value)
+			}
+		default:
+			b.WriteRune(v)
+		}
+	}
+}
+
+// stringToken
+// @Description: 读取字符串剩余部分并生成对应的 Token
+// @receiver l
+// @param offset: 字面量起始位置
+// @param tp: 字符串正常结束时的类型
+// @param interp: 遇到 ${ 时的类型
+// @return *token.Token
+func (l *Lexer) stringToken(offset int, tp token.Type, interp token.Type) *token.Token {
+	value, ok := l.string(offset)
+	if ok {
+		tp = interp
+	}
+	return token.NewToken(tp, value)
+}
+
+// char
+// @Description: 读取单引号字符字面量并处理转义,调用前起始的单引号已被读取
+// @receiver l
+// @param offset: 字面量起始位置
+// @return string: 转义后的字符
+func (l *Lexer) char(offset int) string {
+	var value rune
+	n := 0
+	for {
+		v := l.peek(0)
+		if v == '\n' || v == 0 {
+			l.error(offset, "rune literal not terminated")
+			return string(value)
+		}
+		l.next()
+		if v == '\'' {
 			break
 		}
+		n++
+		if v == '\\' {
+			if r, _, ok := l.escape('\''); ok {
+				value = r
+			}
+			continue
+		}
+		value = v
 	}
-	ret := l.input[pos:l.pos]
-	return string(ret)
+
+	switch {
+	case n == 0:
+		l.error(offset, "empty rune literal or unescaped ' in rune literal")
+	case n > 1:
+		l.error(offset, "more than one character in rune literal")
+	}
+	return string(value)
 }
 
-func (l *Lexer) skipInterference() {
-	for ; l.pos < l.ln; l.pos++ {
-		switch l.input[l.pos] {
-		case ' ':
-		case '\n':
+// rawString
+// @Description: 读取反引号包裹的原始字符串,可以跨越多行,不处理转义,调用前起始的 '`' 已被读取
+// @receiver l
+// @param offset: 字面量起始位置
+// @return string: 去除 '\r' 后的字符串内容
+func (l *Lexer) rawString(offset int) string {
+	var b strings.Builder
+	l.multiline = true
+	for {
+		v := l.next()
+		switch v {
+		case 0:
+			l.error(offset, "raw string literal not terminated")
+			return b.String()
+		case '`':
+			l.multiline = false
+			return b.String()
 		case '\r':
-		case '\t':
 		default:
-			return
+			b.WriteRune(v)
+		}
+	}
+}
+
+// comment
+// @Description: 读取注释,调用前起始的 '/' 已被读取
+// @receiver l
+// @param offset: 注释起始位置
+// @return string: 包含 // 或 /* */ 的注释原文
+func (l *Lexer) comment(offset int) string {
+	var b strings.Builder
+	b.WriteRune('/')
+	if l.next() == '/' {
+		// line comment, 不包含结尾的换行
+		b.WriteRune('/')
+		for v := l.peek(0); v != '\n' && v != 0; v = l.peek(0) {
+			b.WriteRune(l.next())
+		}
+		return b.String()
+	}
+
+	// block comment
+	b.WriteRune('*')
+	l.multiline = true
+	for {
+		v := l.next()
+		if v == 0 {
+			l.error(offset, "comment not terminated")
+			return b.String()
+		}
+		b.WriteRune(v)
+		if v == '*' && l.peek(0) == '/' {
+			b.WriteRune(l.next())
+			l.multiline = false
+			return b.String()
+		}
+	}
+}
+
+func (l *Lexer) isCommentStart() bool {
+	return l.peek(0) == '/' && (l.peek(1) == '/' || l.peek(1) == '*')
+}
+
+// skipInterference
+// @Description: 跳过空白与注释. 与 Go 相同,当上一个词法单元可以结束语句时,
+// 换行(包括跨行的块注释)处会自动插入分号; EOF 同样可以结束语句,由 parser 处理
+// @receiver l
+// @return int: 自动插入的分号的位置
+// @return bool: 是否需要自动插入分号
+func (l *Lexer) skipInterference() (int, bool) {
+	for {
+		switch l.peek(0) {
+		case '\n':
+			offset := l.pos
+			l.next()
+			if l.insertSemi {
+				return offset, true
+			}
+		case ' ', '\r', '\t':
+			l.next()
+		case '/':
+			if l.mode&ScanComments != 0 || !l.isCommentStart() {
+				return 0, false
+			}
+			offset := l.pos
+			l.next()
+			if comment := l.comment(offset); l.insertSemi && strings.ContainsRune(comment, '\n') {
+				return offset, true
+			}
+		default:
+			return 0, false
 		}
 	}
 }
 
+// canEndStmt 紧跟在这些词法单元之后的换行会自动插入分号
+func canEndStmt(tp token.Type) bool {
+	switch tp {
+	case token.IDENT, token.INT, token.FLOAT, token.IMAG, token.CHAR, token.STRING, token.STRING_TAIL,
+		token.TRUE, token.FALSE, token.BREAK, token.CONTINUE, token.FALLTHROUGH, token.RETURN,
+		token.INC, token.DEC, token.RPAREN, token.RBRACK, token.RBRACE:
+		return true
+	}
+	return false
+}
+
 // NextToken
 // @Description: 解析获取下一个有效的 Token
 // @receiver l
 // @return *token.Token
 func (l *Lexer) NextToken() *token.Token {
+	for {
+		if tk := l.scan(); tk != nil {
+			return tk
+		}
+	}
+}
+
+// scan
+// @Description: 读取一个词法单元,遇到非法字符时记录错误并跳过该字符
+// @receiver l
+// @return *token.Token: 跳过非法字符时返回 nil
+func (l *Lexer) scan() *token.Token {
 	var tk *token.Token
-	l.skipInterference()
+	offset, semi := l.skipInterference()
+	if semi || (l.semiInLine && l.insertSemi) {
+		if !semi {
+			offset = l.pos
+		}
+		l.insertSemi, l.semiInLine = false, false
+		tk = token.NewToken(token.SEMICOLON, "\n")
+		tk.Pos = l.file.Pos(offset)
+		return tk
+	}
+
+	offset = l.pos
 	v := l.next()
 	switch v {
 	case 0:
+		if len(l.interp) > 0 {
+			l.error(offset, "string literal not terminated")
+			l.interp = nil
+		}
 		tk = token.NewToken(token.EOF, "")
 	case '"':
-		tk = token.NewToken(token.STRING, l.string())
-		l.next()
+		tk = l.stringToken(offset, token.STRING, token.STRING_HEAD)
+	case '`':
+		tk = token.NewToken(token.STRING, l.rawString(offset))
+	case '\'':
+		tk = token.NewToken(token.CHAR, l.char(offset))
 	case '+':
 		switch l.peek(0) {
 		case '=':
@@ -142,6 +705,12 @@ func (l *Lexer) NextToken() *token.Token {
 		}
 	case '/':
 		switch l.peek(0) {
+		case '/', '*':
+			// 仅在 ScanComments 模式下到达此处,否则注释已被 skipInterference 跳过
+			tk = token.NewToken(token.COMMENT, l.comment(offset))
+			if l.insertSemi && strings.ContainsRune(tk.Value, '\n') {
+				l.semiInLine = true
+			}
 		case '=':
 			tk = token.NewToken(token.QUO_ASSIGN, "/=")
 			l.next()
@@ -257,21 +826,35 @@ func (l *Lexer) NextToken() *token.Token {
 	case ']':
 		tk = token.NewToken(token.RBRACK, "]")
 	case '{':
+		if n := len(l.interp); n > 0 {
+			l.interp[n-1]++
+		}
 		tk = token.NewToken(token.LBRACE, "{")
 	case '}':
-		tk = token.NewToken(token.RBRACE, "}")
+		n := len(l.interp)
+		switch {
+		case n > 0 && l.interp[n-1] == 0:
+			// 插值结束,继续读取字符串
+			l.interp = l.interp[:n-1]
+			tk = l.stringToken(offset, token.STRING_TAIL, token.STRING_MID)
+		case n > 0:
+			l.interp[n-1]--
+			fallthrough
+		default:
+			tk = token.NewToken(token.RBRACE, "}")
+		}
 	case ',':
 		tk = token.NewToken(token.COMMA, ",")
 	case '.':
-		switch l.peek(0) {
-		case '.':
-			switch l.peek(1) {
-			case '.':
-				tk = token.NewToken(token.ELLIPSIS, "...")
-				l.next()
-				l.next()
-			}
+		switch {
+		case isDigit(l.peek(0)):
+			tk = token.NewToken(l.number(offset, v))
+		case l.peek(0) == '.' && l.peek(1) == '.':
+			tk = token.NewToken(token.ELLIPSIS, "...")
+			l.next()
+			l.next()
 		default:
+			// 单独的 .. 按两个 . 处理
 			tk = token.NewToken(token.PERIOD, ".")
 		}
 	case ';':
@@ -290,22 +873,78 @@ func (l *Lexer) NextToken() *token.Token {
 			identifier := string(v) + l.letter()
 			tk = token.NewToken(token.Lookup(identifier), identifier)
 		case isDigit(v):
-			tk = token.NewToken(token.INT, string(v)+l.digit())
+			tk = token.NewToken(l.number(offset, v))
 		default:
-			tk = token.NewToken(token.ILLEGAL, "")
+			l.error(offset, fmt.Sprintf("illegal character %#U", v))
+			return nil
 		}
 	}
+	tk.Pos = l.file.Pos(offset)
+	// 注释不影响是否插入分号,插值表达式内部不插入分号
+	if tk.Type != token.COMMENT {
+		l.insertSemi = canEndStmt(tk.Type) && len(l.interp) == 0
+	}
+	switch tk.Type {
+	case token.LPAREN, token.LBRACK, token.LBRACE:
+		l.depth++
+	case token.RPAREN, token.RBRACK, token.RBRACE:
+		l.depth--
+	}
 	return tk
 }
 
+// Incomplete
+// @Description: 输入在当前位置结束时是否不完整: 存在未闭合的括号,或位于原始字符串、块注释中.
+// 交互式环境可以据此决定是否继续读取下一行
+// @receiver l
+// @return bool
+func (l *Lexer) Incomplete() bool {
+	return l.depth > 0 || l.multiline
+}
+
+// All
+// @Description: 读取剩余的全部词法单元,结果以 EOF 结尾
+// @receiver l
+// @return []*token.Token
+func (l *Lexer) All() []*token.Token {
+	var tokens []*token.Token
+	for {
+		tk := l.NextToken()
+		tokens = append(tokens, tk)
+		if tk.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
 // NewLexer
 // @Description: 创建新词法解析器
 // @param input:
+// @param opts: 可选配置,如 WithFile
+// @return *Lexer
+func NewLexer(input string, opts ...Option) *Lexer {
+	return newLexer(strings.NewReader(input), utf8.RuneCountInString(input), opts...)
+}
+
+// NewReaderLexer
+// @Description: 创建从 io.Reader 增量读取的词法解析器,只保留有限大小的缓冲区,
+// 适用于较大的脚本文件或管道输入. 未通过 WithFile 指定源文件时,源文件大小视为未知
+// @param r:
+// @param opts: 可选配置,如 WithFile
 // @return *Lexer
-func NewLexer(input string) *Lexer {
+func NewReaderLexer(r io.Reader, opts ...Option) *Lexer {
+	return newLexer(r, -1, opts...)
+}
+
+func newLexer(r io.Reader, size int, opts ...Option) *Lexer {
 	v := &Lexer{
-		input: []rune(input),
+		src: bufio.NewReaderSize(r, bufferSize),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.file == nil {
+		v.file = token.NewFileSet().AddFile("", size)
 	}
-	v.ln = len(v.input)
 	return v
 }
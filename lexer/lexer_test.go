@@ -1,7 +1,10 @@
 package lexer
 
 import (
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 
@@ -100,12 +103,15 @@ func TestLexer_NextToken(t *testing.T) {
 		{Type: token.IDENT, Value: "a"},
 		{Type: token.ADD, Value: "+"},
 		{Type: token.IDENT, Value: "b"},
+		{Type: token.SEMICOLON, Value: "\n"},
 		{Type: token.RBRACE, Value: "}"},
+		{Type: token.SEMICOLON, Value: "\n"},
 		{Type: token.TYPE, Value: "type"},
 		{Type: token.IDENT, Value: "X"},
 		{Type: token.INTERFACE, Value: "interface"},
 		{Type: token.LBRACE, Value: "{"},
 		{Type: token.RBRACE, Value: "}"},
+		{Type: token.SEMICOLON, Value: "\n"},
 		{Type: token.EOF, Value: ""},
 	}
 	for _, tt := range tests {
@@ -114,3 +120,389 @@ func TestLexer_NextToken(t *testing.T) {
 		assert.Equal(t, tt.Value, tk.Value)
 	}
 }
+
+func TestLexer_Illegal(t *testing.T) {
+	l := NewLexer("a @ b $.. c#")
+	tests := []*token.Token{
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.IDENT, Value: "b"},
+		{Type: token.PERIOD, Value: "."},
+		{Type: token.PERIOD, Value: "."},
+		{Type: token.IDENT, Value: "c"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	var errs []string
+	for _, err := range l.Errors() {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		"1:3: illegal character U+0040 '@'",
+		"1:7: illegal character U+0024 '$'",
+		"1:12: illegal character U+0023 '#'",
+	}, errs)
+
+	// 任意字符都不会产生 nil
+	for c := rune(1); c < utf8.RuneSelf; c++ {
+		for _, tk := range NewLexer(string(c) + string(c)).All() {
+			assert.NotNil(t, tk)
+		}
+	}
+
+	// 连续的非法字符逐个跳过
+	l = NewLexer(strings.Repeat("@", 100000) + "a")
+	assert.Equal(t, token.IDENT, l.NextToken().Type)
+	assert.Equal(t, token.EOF, l.NextToken().Type)
+	assert.Equal(t, 100000, len(l.Errors()))
+}
+
+func TestLexer_Position(t *testing.T) {
+	fset := token.NewFileSet()
+	input := "var a = 10\n  add(a,\n\tb)"
+	file := fset.AddFile("test.mini", len([]rune(input)))
+	l := NewLexer(input, WithFile(file))
+	tests := []struct {
+		tp     token.Type
+		line   int
+		column int
+	}{
+		{token.VAR, 1, 1},
+		{token.IDENT, 1, 5},
+		{token.ASSIGN, 1, 7},
+		{token.INT, 1, 9},
+		{token.SEMICOLON, 1, 11},
+		{token.IDENT, 2, 3},
+		{token.LPAREN, 2, 6},
+		{token.IDENT, 2, 7},
+		{token.COMMA, 2, 8},
+		{token.IDENT, 3, 2},
+		{token.RPAREN, 3, 3},
+		{token.EOF, 3, 4},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.tp, tk.Type)
+		pos := fset.Position(tk.Pos)
+		assert.Equal(t, "test.mini", pos.Filename)
+		assert.Equal(t, tt.line, pos.Line)
+		assert.Equal(t, tt.column, pos.Column)
+	}
+}
+
+func TestLexer_Comment(t *testing.T) {
+	input := `// leading comment
+var a = 1 // trailing comment
+/* block
+   comment */ a / 2 /**/`
+	l := NewLexer(input)
+	tests := []*token.Token{
+		{Type: token.VAR, Value: "var"},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.ASSIGN, Value: "="},
+		{Type: token.INT, Value: "1"},
+		{Type: token.SEMICOLON, Value: "\n"},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.QUO, Value: "/"},
+		{Type: token.INT, Value: "2"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	l = NewLexer(input, WithMode(ScanComments))
+	tests = []*token.Token{
+		{Type: token.COMMENT, Value: "// leading comment"},
+		{Type: token.VAR, Value: "var"},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.ASSIGN, Value: "="},
+		{Type: token.INT, Value: "1"},
+		{Type: token.COMMENT, Value: "// trailing comment"},
+		{Type: token.SEMICOLON, Value: "\n"},
+		{Type: token.COMMENT, Value: "/* block\n   comment */"},
+		{Type: token.IDENT, Value: "a"},
+		{Type: token.QUO, Value: "/"},
+		{Type: token.INT, Value: "2"},
+		{Type: token.COMMENT, Value: "/**/"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+
+	l = NewLexer("a /* never closed\n b")
+	assert.Equal(t, token.IDENT, l.NextToken().Type)
+	assert.Equal(t, token.SEMICOLON, l.NextToken().Type)
+	assert.Equal(t, token.EOF, l.NextToken().Type)
+	assert.Equal(t, 1, len(l.Errors()))
+	assert.Equal(t, "1:3: comment not terminated", l.Errors()[0].Error())
+}
+
+func TestLexer_Number(t *testing.T) {
+	l := NewLexer(`0 123 1.5 .5 1. 1e-9 2.5E+3 3e10 1...`)
+	tests := []*token.Token{
+		{Type: token.INT, Value: "0"},
+		{Type: token.INT, Value: "123"},
+		{Type: token.FLOAT, Value: "1.5"},
+		{Type: token.FLOAT, Value: ".5"},
+		{Type: token.FLOAT, Value: "1."},
+		{Type: token.FLOAT, Value: "1e-9"},
+		{Type: token.FLOAT, Value: "2.5E+3"},
+		{Type: token.FLOAT, Value: "3e10"},
+		{Type: token.INT, Value: "1"},
+		{Type: token.ELLIPSIS, Value: "..."},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	l = NewLexer(`1e+`)
+	assert.Equal(t, token.FLOAT, l.NextToken().Type)
+	assert.Equal(t, "1:1: exponent has no digits", l.Errors()[0].Error())
+}
+
+func TestLexer_String(t *testing.T) {
+	l := NewLexer(`"say \"hi\"" "a\tb\n" "\\" "\x41\101\u4e16\U0001F600" "\xe4\xb8\x96" ` + "`raw \\n\r\nline`")
+	tests := []*token.Token{
+		{Type: token.STRING, Value: `say "hi"`},
+		{Type: token.STRING, Value: "a\tb\n"},
+		{Type: token.STRING, Value: `\`},
+		{Type: token.STRING, Value: "AA世😀"},
+		{Type: token.STRING, Value: "世"},
+		{Type: token.STRING, Value: "raw \\n\nline"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{`"abc`, "1:1: string literal not terminated"},
+		{"\"abc\nvar a = 1", "1:1: string literal not terminated"},
+		{"`abc", "1:1: raw string literal not terminated"},
+		{`"\q"`, "1:2: unknown escape sequence"},
+		{`"\x4g"`, "1:5: illegal character U+0067 'g' in escape sequence"},
+		{`"\uD800"`, "1:2: escape sequence is invalid Unicode code point"},
+	}
+	for _, tt := range errs {
+		l = NewLexer(tt.input)
+		assert.Equal(t, token.STRING, l.NextToken().Type)
+		assert.Equal(t, 1, len(l.Errors()))
+		assert.Equal(t, tt.err, l.Errors()[0].Error())
+	}
+
+	l = NewLexer("\"abc\nvar")
+	l.NextToken()
+	assert.Equal(t, token.SEMICOLON, l.NextToken().Type)
+	assert.Equal(t, token.VAR, l.NextToken().Type)
+}
+
+func TestLexer_Interpolation(t *testing.T) {
+	l := NewLexer(`"a${b + {1: 2}[1]}c${"d${e}"}f" "\${g}"` + "\n")
+	tests := []*token.Token{
+		{Type: token.STRING_HEAD, Value: "a"},
+		{Type: token.IDENT, Value: "b"},
+		{Type: token.ADD, Value: "+"},
+		{Type: token.LBRACE, Value: "{"},
+		{Type: token.INT, Value: "1"},
+		{Type: token.COLON, Value: ":"},
+		{Type: token.INT, Value: "2"},
+		{Type: token.RBRACE, Value: "}"},
+		{Type: token.LBRACK, Value: "["},
+		{Type: token.INT, Value: "1"},
+		{Type: token.RBRACK, Value: "]"},
+		{Type: token.STRING_MID, Value: "c"},
+		{Type: token.STRING_HEAD, Value: "d"},
+		{Type: token.IDENT, Value: "e"},
+		{Type: token.STRING_TAIL, Value: ""},
+		{Type: token.STRING_TAIL, Value: "f"},
+		{Type: token.STRING, Value: "${g}"},
+		{Type: token.SEMICOLON, Value: "\n"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	l = NewLexer(`"a${b`)
+	l.All()
+	assert.Equal(t, 1, len(l.Errors()))
+	assert.Equal(t, "1:6: string literal not terminated", l.Errors()[0].Error())
+}
+
+func TestLexer_Char(t *testing.T) {
+	l := NewLexer(`'a' '\n' '\'' '世' '\x41' '世'`)
+	tests := []*token.Token{
+		{Type: token.CHAR, Value: "a"},
+		{Type: token.CHAR, Value: "\n"},
+		{Type: token.CHAR, Value: "'"},
+		{Type: token.CHAR, Value: "世"},
+		{Type: token.CHAR, Value: "A"},
+		{Type: token.CHAR, Value: "世"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{`''`, "1:1: empty rune literal or unescaped ' in rune literal"},
+		{`'ab'`, "1:1: more than one character in rune literal"},
+		{`'a`, "1:1: rune literal not terminated"},
+	}
+	for _, tt := range errs {
+		l = NewLexer(tt.input)
+		assert.Equal(t, token.CHAR, l.NextToken().Type)
+		assert.Equal(t, 1, len(l.Errors()))
+		assert.Equal(t, tt.err, l.Errors()[0].Error())
+	}
+}
+
+func TestLexer_IntegerLiteral(t *testing.T) {
+	l := NewLexer(`0xFF 0XaB 0o755 0O17 0b1010 0B1 0755 1_000_000 0x_1F 0b_1_0 1_000.5 09.5`)
+	tests := []*token.Token{
+		{Type: token.INT, Value: "0xFF"},
+		{Type: token.INT, Value: "0XaB"},
+		{Type: token.INT, Value: "0o755"},
+		{Type: token.INT, Value: "0O17"},
+		{Type: token.INT, Value: "0b1010"},
+		{Type: token.INT, Value: "0B1"},
+		{Type: token.INT, Value: "0755"},
+		{Type: token.INT, Value: "1_000_000"},
+		{Type: token.INT, Value: "0x_1F"},
+		{Type: token.INT, Value: "0b_1_0"},
+		{Type: token.FLOAT, Value: "1_000.5"},
+		{Type: token.FLOAT, Value: "09.5"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{`0x`, "1:1: hexadecimal literal has no digits"},
+		{`0b102`, "1:5: invalid digit '2' in binary literal"},
+		{`0o78`, "1:4: invalid digit '8' in octal literal"},
+		{`089`, "1:2: invalid digit '8' in octal literal"},
+		{`1__0`, "1:3: '_' must separate successive digits"},
+		{`10_`, "1:3: '_' must separate successive digits"},
+	}
+	for _, tt := range errs {
+		l = NewLexer(tt.input)
+		assert.Equal(t, token.INT, l.NextToken().Type)
+		assert.Equal(t, 1, len(l.Errors()), tt.input)
+		assert.Equal(t, tt.err, l.Errors()[0].Error())
+		assert.Equal(t, token.EOF, l.NextToken().Type)
+	}
+}
+
+func TestLexer_Imag(t *testing.T) {
+	l := NewLexer(`2i 1.5i .5i 1e3i 0x10i 09i`)
+	tests := []*token.Token{
+		{Type: token.IMAG, Value: "2i"},
+		{Type: token.IMAG, Value: "1.5i"},
+		{Type: token.IMAG, Value: ".5i"},
+		{Type: token.IMAG, Value: "1e3i"},
+		{Type: token.IMAG, Value: "0x10i"},
+		{Type: token.IMAG, Value: "09i"},
+		{Type: token.EOF, Value: ""},
+	}
+	for _, tt := range tests {
+		tk := l.NextToken()
+		assert.Equal(t, tt.Type, tk.Type)
+		assert.Equal(t, tt.Value, tk.Value)
+	}
+	assert.Equal(t, 0, len(l.Errors()))
+}
+
+func TestNewReaderLexer(t *testing.T) {
+	input := "var s = \"世界\" // comment\nfunc add(a, b) {\n\treturn a + b\n}\n" + strings.Repeat("x ", 3000) + "\n1.5e3"
+	want := NewLexer(input)
+	l := NewReaderLexer(iotest.OneByteReader(strings.NewReader(input)))
+	for {
+		expect, tk := want.NextToken(), l.NextToken()
+		assert.Equal(t, expect.Type, tk.Type)
+		assert.Equal(t, expect.Value, tk.Value)
+		assert.Equal(t, want.File().Position(expect.Pos), l.File().Position(tk.Pos))
+		if tk.Type == token.EOF {
+			break
+		}
+	}
+	assert.Equal(t, -1, l.File().Size())
+	assert.Equal(t, 0, len(l.Errors()))
+
+	l = NewReaderLexer(iotest.TimeoutReader(strings.NewReader("a b")))
+	assert.Equal(t, token.IDENT, l.NextToken().Type)
+	assert.Equal(t, token.IDENT, l.NextToken().Type)
+	assert.Equal(t, token.EOF, l.NextToken().Type)
+	assert.Equal(t, "1:4: read error: timeout", l.Errors()[0].Error())
+}
+
+func TestLexer_Incomplete(t *testing.T) {
+	tests := []struct {
+		input      string
+		incomplete bool
+	}{
+		{"x := 1", false},
+		{"func f() {", true},
+		{"f(1,", true},
+		{"a := [1, [2]", true},
+		{"func f() {\n\treturn 1\n}", false},
+		{"x := `abc", true},
+		{"x := `abc\n`", false},
+		{"/* comment", true},
+		{"/* comment */ // line", false},
+		{`"${ f() }" {`, true},
+		{"}", false},
+		{`"abc`, false},
+	}
+	for _, tt := range tests {
+		l := NewLexer(tt.input)
+		l.All()
+		assert.Equal(t, tt.incomplete, l.Incomplete(), tt.input)
+	}
+}
+
+func TestLexer_All(t *testing.T) {
+	tokens := NewLexer(`print(1)`).All()
+	expect := []token.Type{token.IDENT, token.LPAREN, token.INT, token.RPAREN, token.EOF}
+	assert.Equal(t, len(expect), len(tokens))
+	for i, tp := range expect {
+		assert.Equal(t, tp, tokens[i].Type)
+	}
+	assert.Equal(t, []*token.Token{{Type: token.EOF, Value: "", Pos: 1}}, NewLexer("").All())
+}
@@ -7,5 +7,8 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "tokens" {
+		os.Exit(tokens(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
 	repl.Start(os.Stdin, os.Stdout)
 }
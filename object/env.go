@@ -5,6 +5,7 @@ import "sync"
 type Env struct {
 	sync.Mutex
 	store  map[string]Object
+	consts map[string]bool // store 中以常量绑定的名字
 	parent *Env
 }
 
@@ -32,6 +33,67 @@ func (e *Env) Set(key string, obj Object) Object {
 	return old
 }
 
+// SetConst 在当前作用域中绑定常量,常量之后不能被赋值,也不能在同一作用域中重新声明
+func (e *Env) SetConst(key string, obj Object) {
+	e.Lock()
+	defer e.Unlock()
+	if e.store == nil {
+		e.store = make(map[string]Object)
+	}
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.store[key] = obj
+	e.consts[key] = true
+}
+
+// IsConst key 最近的绑定是否为常量,沿父作用域向上查找
+func (e *Env) IsConst(key string) bool {
+	e.Lock()
+	_, ok := e.store[key]
+	constant := e.consts[key]
+	e.Unlock()
+	if ok {
+		return constant
+	}
+	if e.parent != nil {
+		return e.parent.IsConst(key)
+	}
+	return false
+}
+
+// IsLocal key 是否已在当前作用域中绑定,不查找父作用域
+func (e *Env) IsLocal(key string) bool {
+	e.Lock()
+	defer e.Unlock()
+	_, ok := e.store[key]
+	return ok
+}
+
+// IsLocalConst key 是否为当前作用域中的常量,内层作用域可以遮蔽外层的常量
+func (e *Env) IsLocalConst(key string) bool {
+	e.Lock()
+	defer e.Unlock()
+	return e.consts[key]
+}
+
+// Assign 修改已有的绑定,沿父作用域向上查找,未找到时返回 false
+func (e *Env) Assign(key string, obj Object) bool {
+	e.Lock()
+	_, ok := e.store[key]
+	if ok {
+		e.store[key] = obj
+	}
+	e.Unlock()
+	if ok {
+		return true
+	}
+	if e.parent != nil {
+		return e.parent.Assign(key, obj)
+	}
+	return false
+}
+
 func NewEnv(parent *Env) *Env {
 	return &Env{parent: parent}
 }
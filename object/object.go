@@ -1,7 +1,9 @@
 package object
 
 import (
+	"encoding/binary"
 	"hash/fnv"
+	"math"
 	"strconv"
 	"strings"
 
@@ -18,10 +20,15 @@ type BuiltinFunc func(args ...Object) Object
 
 const (
 	INT      Type = "INT"
+	FLOAT    Type = "FLOAT"
+	COMPLEX  Type = "COMPLEX"
 	String   Type = "STRING"
+	CHAR     Type = "CHAR"
 	BOOL     Type = "BOOL"
 	NIL      Type = "NIL"
 	RETURN   Type = "RETURN"
+	BREAK    Type = "BREAK"
+	CONTINUE Type = "CONTINUE"
 	ERROR    Type = "ERROR"
 	FUNCTION Type = "FUNCTION"
 	BUILTIN  Type = "BUILTIN"
@@ -54,6 +61,25 @@ func (i *Integer) Type() Type      { return INT }
 func (i *Integer) Inspect() string { return strconv.Itoa(int(i.Value)) }
 func (i *Integer) MapKey() MapKey  { return MapKey{Type: i.Type(), Value: uint64(i.Value)} }
 
+type Float struct{ Value float64 }
+
+func (f *Float) Type() Type      { return FLOAT }
+func (f *Float) Inspect() string { return strconv.FormatFloat(f.Value, 'g', -1, 64) }
+func (f *Float) MapKey() MapKey  { return MapKey{Type: f.Type(), Value: math.Float64bits(f.Value)} }
+
+type Complex struct{ Value complex128 }
+
+func (c *Complex) Type() Type      { return COMPLEX }
+func (c *Complex) Inspect() string { return strconv.FormatComplex(c.Value, 'g', -1, 128) }
+func (c *Complex) MapKey() MapKey {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], math.Float64bits(real(c.Value)))
+	binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(imag(c.Value)))
+	h := fnv.New64a()
+	_, _ = h.Write(buf[:])
+	return MapKey{Type: c.Type(), Value: h.Sum64()}
+}
+
 type Stringer struct{ Value string }
 
 func (s Stringer) Type() Type      { return String }
@@ -64,6 +90,12 @@ func (s Stringer) MapKey() MapKey {
 	return MapKey{Type: s.Type(), Value: h.Sum64()}
 }
 
+type Char struct{ Value rune }
+
+func (c *Char) Type() Type      { return CHAR }
+func (c *Char) Inspect() string { return string(c.Value) }
+func (c *Char) MapKey() MapKey  { return MapKey{Type: c.Type(), Value: uint64(c.Value)} }
+
 type Boolean struct{ Value bool }
 
 func (b *Boolean) Type() Type      { return BOOL }
@@ -85,14 +117,29 @@ type Return struct{ Value Object }
 func (r *Return) Type() Type      { return RETURN }
 func (r *Return) Inspect() string { return r.Value.Inspect() }
 
+// Break 与 Continue 和 Return 一样沿语句块向上传递,直到被对应的循环处理
+// Label 为空时由最内层的循环处理
+
+type Break struct{ Label string }
+
+func (b *Break) Type() Type      { return BREAK }
+func (b *Break) Inspect() string { return "break " + b.Label }
+
+type Continue struct{ Label string }
+
+func (c *Continue) Type() Type      { return CONTINUE }
+func (c *Continue) Inspect() string { return "continue " + c.Label }
+
 type Error struct{ Error string }
 
 func (e *Error) Type() Type      { return ERROR }
 func (e *Error) Inspect() string { return e.Error }
 
 type Function struct {
-	Name       *ast.Identifier
+	Name       *ast.Identifier // 匿名函数时为 nil
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expr // 参数的默认值,与 Parameters 一一对应,调用时求值
+	Variadic   bool       // 最后一个参数收集多余的实参
 	Body       *ast.BlockStmt
 	Env        *Env
 }
@@ -102,11 +149,22 @@ func (f *Function) Inspect() string {
 	var b strings.Builder
 
 	params := make([]string, 0, len(f.Parameters))
-	for _, p := range f.Parameters {
+	for i, p := range f.Parameters {
+		if f.Defaults != nil && f.Defaults[i] != nil {
+			params = append(params, p.String()+" = "+f.Defaults[i].String())
+			continue
+		}
 		params = append(params, p.String())
 	}
+	if f.Variadic {
+		params[len(params)-1] += "..."
+	}
 
-	b.WriteString("func " + f.Name.String() + " (" + strings.Join(params, ", ") + ") {\n" + f.Body.String() + "\n}")
+	if f.Name == nil {
+		b.WriteString("func(" + strings.Join(params, ", ") + ") {\n" + f.Body.String() + "\n}")
+	} else {
+		b.WriteString("func " + f.Name.String() + " (" + strings.Join(params, ", ") + ") {\n" + f.Body.String() + "\n}")
+	}
 	return b.String()
 }
 
@@ -12,4 +12,34 @@ func Test_mapKey(t *testing.T) {
 	assert.Equal(t, b1.MapKey(), b2.MapKey())
 	i1, i2 := &Integer{Value: 1}, &Integer{Value: 1}
 	assert.Equal(t, i1.MapKey(), i2.MapKey())
+	z1, z2 := &Complex{Value: 1 + 2i}, &Complex{Value: 1 + 2i}
+	assert.Equal(t, z1.MapKey(), z2.MapKey())
+	assert.NotEqual(t, z1.MapKey(), (&Complex{Value: 2 + 1i}).MapKey())
+	c1, c2 := &Char{Value: 'a'}, &Char{Value: 'a'}
+	assert.Equal(t, c1.MapKey(), c2.MapKey())
+	f1, f2 := &Float{Value: 1.5}, &Float{Value: 1.5}
+	assert.Equal(t, f1.MapKey(), f2.MapKey())
+	assert.NotEqual(t, (&Float{Value: 1}).MapKey(), i1.MapKey())
+}
+
+func Test_envConst(t *testing.T) {
+	outer := NewEnv(nil)
+	outer.SetConst("a", &Integer{Value: 1})
+	outer.Set("b", &Integer{Value: 2})
+	inner := NewEnv(outer)
+
+	assert.Equal(t, true, outer.IsConst("a"))
+	assert.Equal(t, true, inner.IsConst("a"))
+	assert.Equal(t, false, inner.IsConst("b"))
+	assert.Equal(t, false, inner.IsConst("c"))
+	assert.Equal(t, true, outer.IsLocalConst("a"))
+	assert.Equal(t, false, inner.IsLocalConst("a"))
+	assert.Equal(t, true, outer.IsLocal("b"))
+	assert.Equal(t, false, inner.IsLocal("b"))
+
+	// 内层作用域中的变量遮蔽外层的常量
+	inner.Set("a", &Integer{Value: 3})
+	assert.Equal(t, false, inner.IsConst("a"))
+	v, _ := outer.Get("a")
+	assert.Equal(t, int64(1), v.(*Integer).Value)
 }
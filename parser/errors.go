@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/songzhibin97/mini-interpreter/token"
+)
+
+// ErrorKind
+// @Description: 语法错误的类别
+type ErrorKind int
+
+const (
+	LexicalError    ErrorKind = iota // 词法错误,如字符串未闭合
+	UnexpectedToken                  // 出现了不符合语法的 Token
+	BadLiteral                       // 无法解析的数字字面量
+	BadAssign                        // 赋值语句的左侧或两侧数量不合法
+	BadParams                        // 参数列表或调用参数不合法
+	BadBranch                        // break/continue/fallthrough 的位置或标签不合法
+	BadStmt                          // 其余不合法的语句结构,如 switch 中有多个 default
+)
+
+var kinds = [...]string{
+	LexicalError:    "lexical error",
+	UnexpectedToken: "unexpected token",
+	BadLiteral:      "bad literal",
+	BadAssign:       "bad assignment",
+	BadParams:       "bad parameters",
+	BadBranch:       "bad branch",
+	BadStmt:         "bad statement",
+}
+
+func (k ErrorKind) String() string {
+	if k >= 0 && int(k) < len(kinds) {
+		return kinds[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// ParseError
+// @Description: 语法错误,包含类别、出错位置与描述
+type ParseError struct {
+	Kind     ErrorKind
+	Pos      token.Position
+	Expected []token.Type // 期望的 Token 类型,仅 UnexpectedToken 有效,为空表示期望一个表达式
+	Got      token.Type   // 实际遇到的 Token 类型,仅 UnexpectedToken 有效
+	Msg      string
+}
+
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// ErrorList
+// @Description: 语法错误列表,与 go/scanner.ErrorList 用法相同
+type ErrorList []*ParseError
+
+func (l ErrorList) Len() int      { return len(l) }
+func (l ErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+func (l ErrorList) Less(i, j int) bool {
+	e, f := &l[i].Pos, &l[j].Pos
+	if e.Filename != f.Filename {
+		return e.Filename < f.Filename
+	}
+	if e.Line != f.Line {
+		return e.Line < f.Line
+	}
+	if e.Column != f.Column {
+		return e.Column < f.Column
+	}
+	return l[i].Msg < l[j].Msg
+}
+
+// Sort
+// @Description: 按文件名、行号、列号与描述排序
+// @receiver l
+func (l ErrorList) Sort() {
+	sort.Sort(l)
+}
+
+// RemoveMultiples
+// @Description: 排序后每一行只保留第一条错误
+// @receiver l
+func (l *ErrorList) RemoveMultiples() {
+	sort.Sort(l)
+	var last token.Position // 初始为无效位置
+	i := 0
+	for _, e := range *l {
+		if e.Pos.Filename != last.Filename || e.Pos.Line != last.Line {
+			last = e.Pos
+			(*l)[i] = e
+			i++
+		}
+	}
+	*l = (*l)[0:i]
+}
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err
+// @Description: 列表为空时返回 nil,否则返回列表本身
+// @receiver l
+// @return error
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/songzhibin97/mini-interpreter/lexer"
+	"github.com/songzhibin97/mini-interpreter/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		kind     ErrorKind
+		line     int
+		column   int
+		expected []token.Type
+		got      token.Type
+	}{
+		{"var = 1", UnexpectedToken, 1, 5, []token.Type{token.IDENT}, token.ASSIGN},
+		{"x := )", UnexpectedToken, 1, 6, nil, token.RPAREN},
+		{"a b", UnexpectedToken, 1, 3, []token.Type{token.SEMICOLON}, token.IDENT},
+		{"a, b 1", UnexpectedToken, 1, 6, []token.Type{token.DEFINE, token.ASSIGN}, token.INT},
+		{"switch { x }", UnexpectedToken, 1, 10, []token.Type{token.CASE, token.DEFAULT, token.RBRACE}, token.IDENT},
+		{"x := 99999999999999999999", BadLiteral, 1, 6, nil, token.ILLEGAL},
+		{"a, b := 1", BadAssign, 1, 6, nil, token.ILLEGAL},
+		{"func f(a = 1, b) {}", BadParams, 1, 15, nil, token.ILLEGAL},
+		{"break", BadBranch, 1, 1, nil, token.ILLEGAL},
+		{"switch { default: default: }", BadStmt, 1, 19, nil, token.ILLEGAL},
+		{"x := \"abc", LexicalError, 1, 6, nil, token.ILLEGAL},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		errs := p.ParseErrors()
+		if !assert.NotEmpty(t, errs, tt.input) {
+			continue
+		}
+		err := errs[0]
+		assert.Equal(t, tt.kind, err.Kind, tt.input)
+		assert.Equal(t, tt.line, err.Pos.Line, tt.input)
+		assert.Equal(t, tt.column, err.Pos.Column, tt.input)
+		assert.Equal(t, tt.expected, err.Expected, tt.input)
+		assert.Equal(t, tt.got, err.Got, tt.input)
+		assert.Equal(t, p.Errors()[0], err.Error(), tt.input)
+	}
+
+	// 修改返回的错误列表不影响解析器
+	p := NewParser(lexer.NewLexer("a b\nvar = 1"))
+	p.ParseProgram()
+	errs := p.ParseErrors()
+	expect := p.Errors()
+	assert.Equal(t, 2, len(expect))
+	errs.Sort()
+	errs[0], errs[1] = errs[1], errs[0]
+	errs[0].Msg = "changed"
+	errs[0].Expected[0] = token.EOF
+	assert.Equal(t, expect, p.Errors())
+	assert.Equal(t, []token.Type{token.SEMICOLON}, p.ParseErrors()[0].Expected)
+
+	p = NewParser(lexer.NewLexer("x := 1"))
+	p.ParseProgram()
+	assert.Nil(t, p.Errors())
+	assert.Nil(t, p.ParseErrors().Err())
+}
+
+func TestErrorList(t *testing.T) {
+	pos := func(file string, line, column int) token.Position {
+		return token.Position{Filename: file, Line: line, Column: column}
+	}
+	list := ErrorList{
+		{Pos: pos("b.mini", 1, 1), Msg: "b1"},
+		{Pos: pos("a.mini", 2, 5), Msg: "a2 second"},
+		{Pos: pos("a.mini", 2, 1), Msg: "a2 first"},
+		{Pos: pos("a.mini", 1, 3), Msg: "a1"},
+		{Pos: pos("a.mini", 2, 1), Msg: "a2 duplicate"},
+	}
+	assert.Equal(t, "b.mini:1:1: b1 (and 4 more errors)", list.Error())
+
+	list.Sort()
+	var msgs []string
+	for _, err := range list {
+		msgs = append(msgs, err.Msg)
+	}
+	assert.Equal(t, []string{"a1", "a2 duplicate", "a2 first", "a2 second", "b1"}, msgs)
+
+	list.RemoveMultiples()
+	msgs = msgs[:0]
+	for _, err := range list {
+		msgs = append(msgs, err.Error())
+	}
+	assert.Equal(t, []string{"a.mini:1:3: a1", "a.mini:2:1: a2 duplicate", "b.mini:1:1: b1"}, msgs)
+
+	var err error = list.Err()
+	assert.NotNil(t, err)
+	assert.Equal(t, "a.mini:1:3: a1 (and 2 more errors)", err.Error())
+	assert.Equal(t, "no errors", ErrorList(nil).Error())
+	assert.Nil(t, ErrorList(nil).Err())
+	assert.Equal(t, "unexpected token", UnexpectedToken.String())
+}
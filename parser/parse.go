@@ -3,6 +3,8 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/songzhibin97/mini-interpreter/ast"
 	"github.com/songzhibin97/mini-interpreter/lexer"
@@ -16,12 +18,22 @@ type Parser struct {
 	l         *lexer.Lexer
 	curToken  *token.Token
 	peekToken *token.Token
-	errors    []string
+	errors    ErrorList
+	lexErrors int // 已并入 errors 的词法错误数量
+	braces    int // 当前 Token 之前尚未闭合的 { 数量,用于出错后定位所在块的 }
+
+	branches []branchTarget // 外层可以被 break/continue 的循环与 switch,进入函数体时清空
 
 	prefixParseHandler map[token.Type]prefixParserFunc
 	infixParseHandler  map[token.Type]infixParserFunc
 }
 
+// branchTarget 可以被 break/continue 的语句
+type branchTarget struct {
+	label string // 语句的标签,没有时为空
+	loop  bool   // 只有循环可以被 continue
+}
+
 func (p *Parser) registerPrefix(t token.Type, fn prefixParserFunc) {
 	if p.prefixParseHandler == nil {
 		p.prefixParseHandler = make(map[token.Type]prefixParserFunc)
@@ -40,23 +52,118 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{
 		Stmts: []ast.Stmt{},
 	}
-	for ; p.curToken.Type != token.EOF; p.nextToken() {
-		stmt := p.parseStmt()
-		if stmt == nil {
+	program.Stmts = append(program.Stmts, p.parseStmtList(p.parseStmt)...)
+	return program
+}
+
+// parseStmtList 解析语句直到当前 Token 为 end 中的任意一个或 EOF.
+// 语句出错时跳过它剩余的 Token 继续解析后面的语句,从而一次报告所有相互独立的错误,
+// 没有完整解析的语句(包括其后缺少分号或换行的语句)不会出现在结果中
+func (p *Parser) parseStmtList(parse func() ast.Stmt, end ...token.Type) []ast.Stmt {
+	var stmts []ast.Stmt
+	base := p.braces
+	for !p.assertionCurToken(token.EOF) && !p.curTokenIn(end) {
+		if p.assertionCurToken(token.SEMICOLON) {
+			// 空语句
+			p.nextToken()
 			continue
 		}
-		program.Stmts = append(program.Stmts, stmt)
+		if stmt := parse(); stmt != nil && p.expectSemi() {
+			stmts = append(stmts, stmt)
+			p.nextToken()
+			continue
+		}
+
+		p.sync(base)
+		if p.curTokenIn(end) {
+			// 出错的位置就是所在块的 }
+			break
+		}
+		p.nextToken()
 	}
-	return program
+	return stmts
 }
 
+// sync 跳过出错语句剩余的 Token,停在结束该语句的分号或所在块的 } 上,或者 case、default 与 EOF 之前.
+// base 为语句所在块的 { 嵌套层数,出错语句中嵌套的块会被整体跳过
+func (p *Parser) sync(base int) {
+	for !p.assertionCurToken(token.EOF) && !p.assertionPeekToken(token.EOF) {
+		if p.depth() == base && (p.assertionCurToken(token.SEMICOLON) || p.assertionCurToken(token.RBRACE) ||
+			p.assertionPeekToken(token.RBRACE) || p.assertionPeekToken(token.CASE) || p.assertionPeekToken(token.DEFAULT)) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// depth 当前 Token 所在的 { 嵌套层数,当前 Token 为 { 时计入它自身
+func (p *Parser) depth() int {
+	if p.assertionCurToken(token.LBRACE) {
+		return p.braces + 1
+	}
+	return p.braces
+}
+
+func (p *Parser) curTokenIn(types []token.Type) bool {
+	for _, t := range types {
+		if p.assertionCurToken(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseErrors
+// @Description: 获取目前为止遇到的词法与语法错误,按发现的顺序排列.
+// 返回的是副本,排序或修改不会影响解析器记录的错误
+// @receiver p
+// @return ErrorList
+func (p *Parser) ParseErrors() ErrorList {
+	var list ErrorList
+	for _, err := range p.errors {
+		e := *err
+		e.Expected = append([]token.Type(nil), err.Expected...)
+		list = append(list, &e)
+	}
+	return list
+}
+
+// Errors
+// @Description: 以字符串形式获取错误,格式为 file:line:col: msg,保留用于兼容
+// @receiver p
+// @return []string
 func (p *Parser) Errors() []string {
-	return p.errors
+	if len(p.errors) == 0 {
+		return nil
+	}
+	errs := make([]string, 0, len(p.errors))
+	for _, err := range p.errors {
+		errs = append(errs, err.Error())
+	}
+	return errs
 }
 
 func (p *Parser) nextToken() {
+	if p.curToken != nil {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			p.braces++
+		case token.RBRACE:
+			// 多余的 } 不会关闭任何块
+			if p.braces > 0 {
+				p.braces--
+			}
+		}
+	}
+
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	// 词法错误与语法错误一并报告
+	for errs := p.l.Errors(); p.lexErrors < len(errs); p.lexErrors++ {
+		err := errs[p.lexErrors]
+		p.errors = append(p.errors, &ParseError{Kind: LexicalError, Pos: err.Pos, Msg: err.Msg})
+	}
 }
 
 func (p *Parser) assertionCurToken(t token.Type) bool {
@@ -67,8 +174,49 @@ func (p *Parser) assertionPeekToken(t token.Type) bool {
 	return p.peekToken.Type == t
 }
 
+// errorf 记录一条 kind 类别的语法错误
+func (p *Parser) errorf(kind ErrorKind, pos token.Pos, format string, args ...interface{}) {
+	p.errors = append(p.errors, &ParseError{Kind: kind, Pos: p.l.File().Position(pos), Msg: fmt.Sprintf(format, args...)})
+}
+
+// lexErrorAt 词法分析器是否已经报告了 tk 中的错误,用于避免对同一个字面量重复报错
+func (p *Parser) lexErrorAt(tk *token.Token) bool {
+	start := p.l.File().Position(tk.Pos).Offset
+	end := start + utf8.RuneCountInString(tk.Value)
+	for _, err := range p.errors {
+		if err.Kind == LexicalError && err.Pos.Offset >= start && err.Pos.Offset < end {
+			return true
+		}
+	}
+	return false
+}
+
+// unexpectedf 记录一条 UnexpectedToken 错误,tk 为实际遇到的 Token,expected 为空表示此处需要表达式
+func (p *Parser) unexpectedf(tk *token.Token, expected []token.Type, format string, args ...interface{}) {
+	p.errors = append(p.errors, &ParseError{
+		Kind:     UnexpectedToken,
+		Pos:      p.l.File().Position(tk.Pos),
+		Expected: expected,
+		Got:      tk.Type,
+		Msg:      fmt.Sprintf(format, args...),
+	})
+}
+
 func (p *Parser) assertionPeekTokenErr(t token.Type) {
-	p.errors = append(p.errors, fmt.Sprintf("expected token %s, got %s", t, p.peekToken.Type))
+	p.unexpectedf(p.peekToken, []token.Type{t}, "expected token %s, got %s", t, p.peekToken.Type)
+}
+
+// expectSemi 语句以分号结束(换行处由 lexer 自动插入),在 } 与 EOF 之前可以省略
+func (p *Parser) expectSemi() bool {
+	switch p.peekToken.Type {
+	case token.SEMICOLON:
+		p.nextToken()
+	case token.RBRACE, token.EOF:
+	default:
+		p.unexpectedf(p.peekToken, []token.Type{token.SEMICOLON}, "expected ; or newline after statement, got %s", p.peekToken.Type)
+		return false
+	}
+	return true
 }
 
 func (p *Parser) forecastNextPeek(t token.Type) bool {
@@ -85,12 +233,13 @@ func (p *Parser) forecastNextPeek(t token.Type) bool {
 func (p *Parser) parseExpr(precedence int) ast.Expr {
 	prefix := p.prefixParseHandler[p.curToken.Type]
 	if prefix == nil {
-		p.errors = append(p.errors, fmt.Sprintf("no prefix parse function for %s found", p.curToken.Type))
+		p.unexpectedf(p.curToken, nil, "no prefix parse function for %s found", p.curToken.Type)
 		return nil
 	}
 	leftExpr := prefix()
 
-	for precedence < p.peekToken.Type.Precedence() {
+	// 子表达式出错时整个表达式都返回 nil,不把 nil 放进语法树
+	for leftExpr != nil && precedence < p.peekToken.Type.Precedence() {
 		infix := p.infixParseHandler[p.peekToken.Type]
 		if infix == nil {
 			return leftExpr
@@ -108,16 +257,87 @@ func (p *Parser) parseIdentifierExpr() ast.Expr {
 func (p *Parser) parseIntegerExpr() ast.Expr {
 	v, err := strconv.ParseInt(p.curToken.Value, 0, 64)
 	if err != nil {
-		p.errors = append(p.errors, fmt.Sprintf("could not parse %s as integer", p.curToken.Value))
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			p.errorf(BadLiteral, p.curToken.Pos, "integer literal %s overflows int64", p.curToken.Value)
+			return nil
+		}
+		if !p.lexErrorAt(p.curToken) {
+			p.errorf(BadLiteral, p.curToken.Pos, "could not parse %s as integer", p.curToken.Value)
+		}
 		return nil
 	}
 	return &ast.Integer{Token: p.curToken, Value: v}
 }
 
+func (p *Parser) parseFloatExpr() ast.Expr {
+	v, err := strconv.ParseFloat(p.curToken.Value, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			p.errorf(BadLiteral, p.curToken.Pos, "float literal %s overflows float64", p.curToken.Value)
+			return nil
+		}
+		if !p.lexErrorAt(p.curToken) {
+			p.errorf(BadLiteral, p.curToken.Pos, "could not parse %s as float", p.curToken.Value)
+		}
+		return nil
+	}
+	return &ast.Float{Token: p.curToken, Value: v}
+}
+
+func (p *Parser) parseImagExpr() ast.Expr {
+	lit := strings.TrimSuffix(p.curToken.Value, "i")
+
+	var v float64
+	var err error
+	if len(lit) > 1 && lit[0] == '0' && strings.ContainsRune("xXoObB", rune(lit[1])) {
+		var i int64
+		i, err = strconv.ParseInt(lit, 0, 64)
+		v = float64(i)
+	} else {
+		// 09i 等 0 开头的虚数按十进制解析
+		v, err = strconv.ParseFloat(lit, 64)
+	}
+	if err != nil {
+		if !p.lexErrorAt(p.curToken) {
+			p.errorf(BadLiteral, p.curToken.Pos, "could not parse %s as imaginary", p.curToken.Value)
+		}
+		return nil
+	}
+	return &ast.Imag{Token: p.curToken, Value: complex(0, v)}
+}
+
 func (p *Parser) parseStringExpr() ast.Expr {
 	return &ast.String{Token: p.curToken, Value: p.curToken.Value}
 }
 
+func (p *Parser) parseInterpolatedStringExpr() ast.Expr {
+	expr := &ast.InterpolatedString{Token: p.curToken}
+	expr.Parts = append(expr.Parts, &ast.String{Token: p.curToken, Value: p.curToken.Value})
+
+	for !p.assertionCurToken(token.STRING_TAIL) {
+		p.nextToken()
+		part := p.parseExpr(token.LowestPrec)
+		if part == nil {
+			return nil
+		}
+		expr.Parts = append(expr.Parts, part)
+
+		switch {
+		case p.assertionPeekToken(token.STRING_MID):
+			p.nextToken()
+		case !p.forecastNextPeek(token.STRING_TAIL):
+			return nil
+		}
+		expr.Parts = append(expr.Parts, &ast.String{Token: p.curToken, Value: p.curToken.Value})
+	}
+	return expr
+}
+
+func (p *Parser) parseCharExpr() ast.Expr {
+	v, _ := utf8.DecodeRuneInString(p.curToken.Value)
+	return &ast.Char{Token: p.curToken, Value: v}
+}
+
 func (p *Parser) parsePrefixExpr() ast.Expr {
 	expr := &ast.PrefixExpr{
 		Token:    p.curToken,
@@ -125,7 +345,9 @@ func (p *Parser) parsePrefixExpr() ast.Expr {
 	}
 	p.nextToken()
 
-	expr.Right = p.parseExpr(token.UnaryPrec)
+	if expr.Right = p.parseExpr(token.UnaryPrec); expr.Right == nil {
+		return nil
+	}
 	return expr
 }
 
@@ -140,6 +362,9 @@ func (p *Parser) parseGroupedExpr() ast.Expr {
 	p.nextToken()
 
 	expr := p.parseExpr(token.LowestPrec)
+	if expr == nil {
+		return nil
+	}
 
 	if !p.forecastNextPeek(token.RPAREN) {
 		return nil
@@ -147,32 +372,82 @@ func (p *Parser) parseGroupedExpr() ast.Expr {
 	return expr
 }
 
+// parseIfExpr 条件两侧的括号可以省略,条件前可以有以 ; 结束的初始化语句
 func (p *Parser) parseIfExpr() ast.Expr {
 	expr := &ast.IfExpr{Token: p.curToken}
-	if !p.forecastNextPeek(token.LPAREN) {
+	if p.assertionPeekToken(token.LBRACE) {
+		p.errorf(BadStmt, p.peekToken.Pos, "missing condition in if statement")
 		return nil
 	}
 
 	p.nextToken()
-	expr.Condition = p.parseExpr(token.LowestPrec)
-
-	if !p.forecastNextPeek(token.RPAREN) {
+	var cond ast.Stmt
+	condToken := p.curToken
+	if !p.assertionCurToken(token.SEMICOLON) {
+		if cond = p.parseSimpleStmt(false); cond == nil {
+			return nil
+		}
+		// 换行处自动插入的分号不能作为初始化语句的结束
+		if p.assertionPeekToken(token.SEMICOLON) && p.peekToken.Value != "\n" {
+			p.nextToken()
+		}
+	}
+	if p.assertionCurToken(token.SEMICOLON) {
+		expr.Init, cond = cond, nil
+		if p.assertionPeekToken(token.LBRACE) {
+			p.errorf(BadStmt, p.peekToken.Pos, "missing condition in if statement")
+			// 跳过初始化语句后的 ;,从 { 开始同步,否则会停在这个 ; 上
+			p.nextToken()
+			return nil
+		}
+		p.nextToken()
+		condToken = p.curToken
+		if cond = p.parseSimpleStmt(false); cond == nil {
+			return nil
+		}
+	}
+	c, ok := cond.(*ast.ExprStmt)
+	if !ok {
+		p.errorf(BadStmt, condToken.Pos, "expected if condition, got %s", cond)
 		return nil
 	}
+	expr.Condition = c.Expr
 
 	if !p.forecastNextPeek(token.LBRACE) {
+		// { 写在下一行时跳过这个块,避免把它当作 map 字面量再报告一次错误
+		if p.assertionPeekToken(token.SEMICOLON) && p.peekToken.Value == "\n" {
+			p.nextToken()
+			if p.assertionPeekToken(token.LBRACE) {
+				p.nextToken()
+				p.parseBlockStmt()
+			}
+		}
 		return nil
 	}
 
-	expr.Consequence = p.parseBlockStmt()
+	if expr.Consequence = p.parseBlockStmt(); expr.Consequence == nil {
+		return nil
+	}
 
 	if p.assertionPeekToken(token.ELSE) {
 		p.nextToken()
 
+		if p.assertionPeekToken(token.IF) {
+			p.nextToken()
+			tk := p.curToken
+			alt := p.parseIfExpr()
+			if alt == nil {
+				return nil
+			}
+			expr.Alternative = &ast.BlockStmt{Token: tk, Stmts: []ast.Stmt{&ast.ExprStmt{Token: tk, Expr: alt}}}
+			return expr
+		}
 		if !p.forecastNextPeek(token.LBRACE) {
 			return nil
 		}
-		expr.Alternative = p.parseBlockStmt()
+		if expr.Alternative = p.parseBlockStmt(); expr.Alternative == nil {
+			return nil
+		}
 	}
 	return expr
 }
@@ -180,31 +455,51 @@ func (p *Parser) parseIfExpr() ast.Expr {
 func (p *Parser) parseFuncExpr() ast.Expr {
 	f := &ast.FuncExpr{Token: p.curToken}
 
-	if !p.forecastNextPeek(token.IDENT) {
-		return nil
-	}
-	f.Name = &ast.Identifier{
-		Token: p.curToken,
-		Value: p.curToken.Value,
+	// 省略函数名时为匿名函数
+	if p.assertionPeekToken(token.IDENT) {
+		p.nextToken()
+		f.Name = &ast.Identifier{
+			Token: p.curToken,
+			Value: p.curToken.Value,
+		}
 	}
 
 	if !p.forecastNextPeek(token.LPAREN) {
 		return nil
 	}
 
-	f.Params = p.parseFuncParams()
+	var ok bool
+	if f.Params, f.Defaults, f.Variadic, ok = p.parseFuncParams(); !ok {
+		return nil
+	}
 
 	if !p.forecastNextPeek(token.LBRACE) {
 		return nil
 	}
 
-	f.Body = p.parseBlockStmt()
+	if f.Body = p.parseFuncBody(); f.Body == nil {
+		return nil
+	}
 
 	return f
 }
 
+// parseFuncBody 函数体中的 break/continue 不能跳出到函数外的循环
+func (p *Parser) parseFuncBody() *ast.BlockStmt {
+	branches := p.branches
+	p.branches = nil
+	body := p.parseBlockStmt()
+	p.branches = branches
+	return body
+}
+
 func (p *Parser) parseArrayExpr() ast.Expr {
-	return &ast.Array{Token: p.curToken, Elements: p.parseElements(token.RBRACK)}
+	expr := &ast.Array{Token: p.curToken}
+	var ok bool
+	if expr.Elements, ok = p.parseElements(token.RBRACK); !ok {
+		return nil
+	}
+	return expr
 }
 
 func (p *Parser) parseMapExpr() ast.Expr {
@@ -213,6 +508,9 @@ func (p *Parser) parseMapExpr() ast.Expr {
 	for !p.assertionPeekToken(token.RBRACE) {
 		p.nextToken()
 		key := p.parseExpr(token.LowestPrec)
+		if key == nil {
+			return nil
+		}
 
 		if !p.forecastNextPeek(token.COLON) {
 			return nil
@@ -220,6 +518,9 @@ func (p *Parser) parseMapExpr() ast.Expr {
 		p.nextToken()
 
 		value := p.parseExpr(token.LowestPrec)
+		if value == nil {
+			return nil
+		}
 		mp.Elements[key] = value
 
 		if !p.assertionPeekToken(token.RBRACE) && !p.forecastNextPeek(token.COMMA) {
@@ -249,13 +550,27 @@ func (p *Parser) parseMacroExpr() ast.Expr {
 		return nil
 	}
 
-	expr.Params = p.parseFuncParams()
+	params, defaults, variadic, ok := p.parseFuncParams()
+	if !ok {
+		return nil
+	}
+	if variadic {
+		p.errorf(BadParams, params[len(params)-1].Token.Pos, "macro cannot have variadic parameters")
+		return nil
+	}
+	if defaults != nil {
+		p.errorf(BadParams, params[0].Token.Pos, "macro cannot have default parameter values")
+		return nil
+	}
+	expr.Params = params
 
 	if !p.forecastNextPeek(token.LBRACE) {
 		return nil
 	}
 
-	expr.Body = p.parseBlockStmt()
+	if expr.Body = p.parseFuncBody(); expr.Body == nil {
+		return nil
+	}
 
 	return expr
 }
@@ -268,18 +583,73 @@ func (p *Parser) parseInfixExpr(left ast.Expr) ast.Expr {
 	}
 	precedence := p.curToken.Type.Precedence()
 	p.nextToken()
-	expr.Right = p.parseExpr(precedence)
+	if expr.Right = p.parseExpr(precedence); expr.Right == nil {
+		return nil
+	}
 	return expr
 }
 
 func (p *Parser) parseCallExpr(left ast.Expr) ast.Expr {
-	return &ast.CallExpr{Token: p.curToken, Func: left, Args: p.parseElements(token.RPAREN)}
+	expr := &ast.CallExpr{Token: p.curToken, Func: left}
+	var ok bool
+	if expr.Args, expr.Keywords, ok = p.parseCallArgs(); !ok {
+		return nil
+	}
+	return expr
+}
+
+// parseCallArgs 解析调用参数, <参数名>: <表达式> 为关键字参数,只能位于位置参数之后
+func (p *Parser) parseCallArgs() ([]ast.Expr, []*ast.KeywordArg, bool) {
+	var args []ast.Expr
+	var keywords []*ast.KeywordArg
+	seen := make(map[string]bool)
+
+	for !p.assertionPeekToken(token.RPAREN) {
+		p.nextToken()
+		if p.assertionCurToken(token.IDENT) && p.assertionPeekToken(token.COLON) {
+			keyword := &ast.KeywordArg{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}}
+			if seen[keyword.Name.Value] {
+				p.errorf(BadParams, keyword.Token.Pos, "duplicate keyword argument %s", keyword.Name.Value)
+				return nil, nil, false
+			}
+			seen[keyword.Name.Value] = true
+			p.nextToken()
+			p.nextToken()
+			if keyword.Value = p.parseExpr(token.LowestPrec); keyword.Value == nil {
+				return nil, nil, false
+			}
+			keywords = append(keywords, keyword)
+		} else {
+			if len(keywords) != 0 {
+				p.errorf(BadParams, p.curToken.Pos, "positional argument follows keyword argument")
+				return nil, nil, false
+			}
+			arg := p.parseElement()
+			if arg == nil {
+				return nil, nil, false
+			}
+			args = append(args, arg)
+		}
+
+		if !p.assertionPeekToken(token.COMMA) {
+			break
+		}
+		// 允许末尾多余的逗号
+		p.nextToken()
+	}
+
+	if !p.forecastNextPeek(token.RPAREN) {
+		return nil, nil, false
+	}
+	return args, keywords, true
 }
 
 func (p *Parser) parseIndexExpr(left ast.Expr) ast.Expr {
 	expr := &ast.IndexExpr{Token: p.curToken, Left: left}
 	p.nextToken()
-	expr.Index = p.parseExpr(token.LowestPrec)
+	if expr.Index = p.parseExpr(token.LowestPrec); expr.Index == nil {
+		return nil
+	}
 
 	if !p.forecastNextPeek(token.RBRACK) {
 		return nil
@@ -287,51 +657,104 @@ func (p *Parser) parseIndexExpr(left ast.Expr) ast.Expr {
 	return expr
 }
 
-func (p *Parser) parseFuncParams() []*ast.Identifier {
-	var params []*ast.Identifier
+// parseFuncParams 解析参数列表,参数可以用 = 指定默认值,
+// 最后一个参数可以以 ... 结尾表示可变参数
+func (p *Parser) parseFuncParams() (params []*ast.Identifier, defaults []ast.Expr, variadic bool, ok bool) {
+	var hasDefault bool
 
 	if p.assertionPeekToken(token.RPAREN) {
 		p.nextToken()
-		return params
+		return params, nil, false, true
 	}
 	p.nextToken()
 
-	params = append(params, &ast.Identifier{Token: p.curToken, Value: p.curToken.Value})
+	for {
+		if variadic {
+			p.errorf(BadParams, params[len(params)-1].Token.Pos, "can only use ... with final parameter in list")
+			return nil, nil, false, false
+		}
+		if !p.assertionCurToken(token.IDENT) {
+			p.unexpectedf(p.curToken, []token.Type{token.IDENT}, "expected token %s, got %s", token.IDENT, p.curToken.Type)
+			return nil, nil, false, false
+		}
+		param := &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+		params = append(params, param)
+
+		var value ast.Expr
+		switch {
+		case p.assertionPeekToken(token.ELLIPSIS):
+			p.nextToken()
+			variadic = true
+			if p.assertionPeekToken(token.ASSIGN) {
+				p.errorf(BadParams, param.Token.Pos, "variadic parameter %s cannot have default value", param.Value)
+				return nil, nil, false, false
+			}
+		case p.assertionPeekToken(token.ASSIGN):
+			p.nextToken()
+			p.nextToken()
+			if value = p.parseExpr(token.LowestPrec); value == nil {
+				return nil, nil, false, false
+			}
+			hasDefault = true
+		case hasDefault:
+			p.errorf(BadParams, param.Token.Pos, "parameter %s without default follows parameter with default", param.Value)
+			return nil, nil, false, false
+		}
+		defaults = append(defaults, value)
 
-	for p.assertionPeekToken(token.COMMA) {
+		if !p.assertionPeekToken(token.COMMA) {
+			break
+		}
 		p.nextToken()
+		// 允许末尾多余的逗号
+		if p.assertionPeekToken(token.RPAREN) {
+			break
+		}
 		p.nextToken()
-		params = append(params, &ast.Identifier{Token: p.curToken, Value: p.curToken.Value})
 	}
 
 	if !p.forecastNextPeek(token.RPAREN) {
-		return nil
+		return nil, nil, false, false
+	}
+	if !hasDefault {
+		defaults = nil
 	}
-	return params
+	return params, defaults, variadic, true
 }
 
-func (p *Parser) parseElements(end token.Type) []ast.Expr {
-	var args []ast.Expr
-
-	if p.assertionPeekToken(end) {
-		p.nextToken()
-		return args
+// parseElement 解析调用参数或数组元素,以 ... 结尾时展开数组
+func (p *Parser) parseElement() ast.Expr {
+	expr := p.parseExpr(token.LowestPrec)
+	if expr == nil || !p.assertionPeekToken(token.ELLIPSIS) {
+		return expr
 	}
-
 	p.nextToken()
-	args = append(args, p.parseExpr(token.LowestPrec))
+	return &ast.SpreadExpr{Token: p.curToken, Value: expr}
+}
 
-	for p.assertionPeekToken(token.COMMA) {
+// parseElements 解析以逗号分隔、以 end 结束的元素列表,任意元素出错时返回 false
+func (p *Parser) parseElements(end token.Type) ([]ast.Expr, bool) {
+	var args []ast.Expr
+
+	for !p.assertionPeekToken(end) {
 		p.nextToken()
+		arg := p.parseElement()
+		if arg == nil {
+			return nil, false
+		}
+		args = append(args, arg)
+
+		if !p.assertionPeekToken(token.COMMA) {
+			break
+		}
+		// 允许末尾多余的逗号
 		p.nextToken()
-		args = append(args, p.parseExpr(token.LowestPrec))
 	}
 
 	if !p.forecastNextPeek(end) {
-		return nil
+		return nil, false
 	}
-
-	return args
+	return args, true
 }
 
 // ============================================================================
@@ -340,14 +763,34 @@ func (p *Parser) parseStmt() ast.Stmt {
 	switch p.curToken.Type {
 	case token.VAR:
 		return p.parseVarStmt()
+	case token.CONST:
+		return p.parseConstStmt()
 	case token.RETURN:
 		return p.parseReturnStmt()
+	case token.FOR:
+		return p.parseForStmt(nil)
+	case token.SWITCH:
+		return p.parseSwitchStmt(nil)
+	case token.BREAK, token.CONTINUE:
+		return p.parseBranchStmt()
+	case token.FALLTHROUGH:
+		// 只能作为 case 的最后一条语句,由 parseCaseClauses 处理
+		p.errorf(BadBranch, p.curToken.Pos, "fallthrough statement out of place")
+		return nil
+	case token.SEMICOLON:
+		// 空语句
+		return nil
+	case token.IDENT:
+		if p.assertionPeekToken(token.COLON) {
+			return p.parseLabeledStmt()
+		}
+		return p.parseSimpleStmt(false)
 	default:
-		return p.parseExprStmt()
+		return p.parseSimpleStmt(false)
 	}
 }
 
-func (p *Parser) parseVarStmt() *ast.VarStmt {
+func (p *Parser) parseVarStmt() ast.Stmt {
 	s := &ast.VarStmt{
 		Token: p.curToken,
 	}
@@ -365,40 +808,537 @@ func (p *Parser) parseVarStmt() *ast.VarStmt {
 
 	p.nextToken()
 
-	s.Value = p.parseExpr(token.LowestPrec)
+	if s.Value = p.parseExpr(token.LowestPrec); s.Value == nil {
+		return nil
+	}
 
 	return s
 }
 
-func (p *Parser) parseReturnStmt() *ast.ReturnStmt {
+// parseConstStmt 解析单个或以括号分组的常量声明
+func (p *Parser) parseConstStmt() ast.Stmt {
+	s := &ast.ConstStmt{Token: p.curToken}
+	if !p.assertionPeekToken(token.LPAREN) {
+		p.nextToken()
+		spec := p.parseConstSpec(nil, 0)
+		if spec == nil {
+			return nil
+		}
+		s.Specs = append(s.Specs, spec)
+		return s
+	}
+
+	p.nextToken()
+	s.Grouped = true
+	ok := true
+	var prev ast.Expr
+	for !p.assertionPeekToken(token.RPAREN) && !p.assertionPeekToken(token.EOF) {
+		p.nextToken()
+		spec := p.parseConstSpec(prev, len(s.Specs))
+		if spec != nil {
+			s.Specs = append(s.Specs, spec)
+			prev = spec.Value
+		} else {
+			// 跳过出错的一项,继续检查后面的项
+			ok = false
+			for !p.assertionCurToken(token.SEMICOLON) &&
+				!p.assertionPeekToken(token.RPAREN) && !p.assertionPeekToken(token.EOF) {
+				p.nextToken()
+			}
+			if p.assertionCurToken(token.SEMICOLON) {
+				continue
+			}
+		}
+
+		if p.assertionPeekToken(token.RPAREN) || p.assertionPeekToken(token.EOF) {
+			break
+		}
+		if !p.forecastNextPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+	if !p.forecastNextPeek(token.RPAREN) || !ok {
+		return nil
+	}
+	return s
+}
+
+// parseConstSpec 解析常量声明中的一项, prev 为分组中上一项的表达式
+func (p *Parser) parseConstSpec(prev ast.Expr, iota int) *ast.ConstSpec {
+	if !p.assertionCurToken(token.IDENT) {
+		p.unexpectedf(p.curToken, []token.Type{token.IDENT}, "expected token %s, got %s", token.IDENT, p.curToken.Type)
+		return nil
+	}
+	spec := &ast.ConstSpec{Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}, Iota: iota}
+
+	switch {
+	case p.assertionPeekToken(token.ASSIGN):
+		p.nextToken()
+		p.nextToken()
+		if spec.Value = p.parseExpr(token.LowestPrec); spec.Value == nil {
+			return nil
+		}
+	case prev != nil:
+		spec.Value, spec.Implicit = prev, true
+	default:
+		p.errorf(BadStmt, spec.Name.Token.Pos, "missing init expr for const declaration")
+		return nil
+	}
+	return spec
+}
+
+func (p *Parser) parseReturnStmt() ast.Stmt {
 	s := &ast.ReturnStmt{
 		Token: p.curToken,
 	}
+	// 不带返回值的 return
+	if p.assertionPeekToken(token.SEMICOLON) || p.assertionPeekToken(token.RBRACE) || p.assertionPeekToken(token.EOF) {
+		return s
+	}
 	p.nextToken()
 
-	s.Value = p.parseExpr(token.LowestPrec)
+	if s.Value = p.parseExpr(token.LowestPrec); s.Value == nil {
+		return nil
+	}
 
 	return s
 }
 
-func (p *Parser) parseExprStmt() *ast.ExprStmt {
-	s := &ast.ExprStmt{
-		Token: p.curToken,
-		Expr:  p.parseExpr(token.LowestPrec),
+// parseSimpleStmt 解析以表达式开头的语句: 表达式语句、赋值、短变量声明与自增自减.
+// rangeOk 为 true 时(for 语句头部)还可以解析 k, v := range x,返回不含循环体的 *ast.RangeStmt
+func (p *Parser) parseSimpleStmt(rangeOk bool) ast.Stmt {
+	tk := p.curToken
+	lhs, starts := p.parseExprList()
+	if lhs == nil {
+		return nil
+	}
+
+	switch p.peekToken.Type {
+	case token.DEFINE, token.ASSIGN, token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN, token.QUO_ASSIGN, token.REM_ASSIGN,
+		token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN, token.SHL_ASSIGN, token.SHR_ASSIGN, token.AND_NOT_ASSIGN:
+		p.nextToken()
+		s := &ast.AssignStmt{Token: p.curToken, Targets: lhs}
+		p.nextToken()
+		if rangeOk && p.assertionCurToken(token.RANGE) && (s.Token.Type == token.ASSIGN || s.Token.Type == token.DEFINE) {
+			return p.parseRangeClause(s.Token, lhs, starts)
+		}
+		if s.Values, _ = p.parseExprList(); s.Values == nil || !p.checkAssign(s, starts) {
+			return nil
+		}
+		return s
 	}
 
+	if len(lhs) > 1 {
+		p.unexpectedf(p.peekToken, []token.Type{token.DEFINE, token.ASSIGN}, "expected := or = after expression list, got %s", p.peekToken.Type)
+		return nil
+	}
+	switch p.peekToken.Type {
+	case token.INC, token.DEC:
+		p.nextToken()
+		if !p.checkAssignTarget(tk, lhs[0]) {
+			return nil
+		}
+		return &ast.IncDecStmt{Token: p.curToken, Target: lhs[0]}
+	}
+
+	return &ast.ExprStmt{Token: tk, Expr: lhs[0]}
+}
+
+// parseExprList 解析以逗号分隔的表达式列表,同时返回每个表达式的起始 Token 用于报错,
+// 任意表达式出错时返回 nil
+func (p *Parser) parseExprList() ([]ast.Expr, []*token.Token) {
+	var list []ast.Expr
+	var starts []*token.Token
+	for {
+		starts = append(starts, p.curToken)
+		expr := p.parseExpr(token.LowestPrec)
+		if expr == nil {
+			return nil, nil
+		}
+		list = append(list, expr)
+		if !p.assertionPeekToken(token.COMMA) {
+			return list, starts
+		}
+		p.nextToken()
+		p.nextToken()
+	}
+}
+
+// checkAssign 检查赋值两侧数量一致,复合赋值只能有一个目标, := 左侧只能是标识符
+func (p *Parser) checkAssign(s *ast.AssignStmt, starts []*token.Token) bool {
+	if s.Token.Type != token.ASSIGN && s.Token.Type != token.DEFINE && (len(s.Targets) > 1 || len(s.Values) > 1) {
+		p.errorf(BadAssign, s.Token.Pos, "assignment operation %s requires single-valued expressions", s.Token.Value)
+		return false
+	}
+	if len(s.Targets) != len(s.Values) {
+		p.errorf(BadAssign, s.Token.Pos, "assignment mismatch: %s but %s", plural(len(s.Targets), "variable"), plural(len(s.Values), "value"))
+		return false
+	}
+	return p.checkAssignTargets(s.Token, s.Targets, starts)
+}
+
+// checkAssignTargets 检查赋值语句或 range 子句左侧的每一个目标
+func (p *Parser) checkAssignTargets(tk *token.Token, targets []ast.Expr, starts []*token.Token) bool {
+	ok := true
+	for i, target := range targets {
+		if _, isIdent := target.(*ast.Identifier); tk.Type == token.DEFINE && !isIdent {
+			p.errorf(BadAssign, starts[i].Pos, "non-name %s on left side of :=", target)
+			ok = false
+			continue
+		}
+		ok = p.checkAssignTarget(starts[i], target) && ok
+	}
+	return ok
+}
+
+// checkAssignTarget 只有标识符与索引表达式可以被赋值
+func (p *Parser) checkAssignTarget(tk *token.Token, expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.Identifier, *ast.IndexExpr:
+		return true
+	default:
+		p.errorf(BadAssign, tk.Pos, "cannot assign to %s", expr)
+		return false
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// parseForStmt
+// 支持 for {}、for cond {}、for init; cond; post {} 与 for k, v := range x {}
+func (p *Parser) parseForStmt(label *ast.Identifier) ast.Stmt {
+	tk := p.curToken
+	var init, cond, post ast.Stmt
+	var condToken *token.Token
+
+	if !p.assertionPeekToken(token.LBRACE) {
+		p.nextToken()
+		if p.assertionCurToken(token.RANGE) {
+			// for range x {}
+			p.nextToken()
+			return p.parseRangeStmt(tk, label, &ast.RangeStmt{X: p.parseExpr(token.LowestPrec)})
+		}
+		if !p.assertionCurToken(token.SEMICOLON) {
+			condToken = p.curToken
+			cond = p.parseSimpleStmt(true)
+			if r, ok := cond.(*ast.RangeStmt); ok {
+				return p.parseRangeStmt(tk, label, r)
+			}
+			if cond == nil {
+				return p.skipLoopHeader(label)
+			}
+			if p.assertionPeekToken(token.SEMICOLON) {
+				p.nextToken()
+			}
+		}
+		if p.assertionCurToken(token.SEMICOLON) {
+			init, cond, condToken = cond, nil, nil
+			if !p.assertionPeekToken(token.SEMICOLON) {
+				p.nextToken()
+				condToken = p.curToken
+				if cond = p.parseSimpleStmt(false); cond == nil {
+					return p.skipLoopHeader(label)
+				}
+			}
+			if !p.forecastNextPeek(token.SEMICOLON) {
+				return p.skipLoopHeader(label)
+			}
+			if !p.assertionPeekToken(token.LBRACE) {
+				p.nextToken()
+				if post = p.parseSimpleStmt(false); post == nil {
+					return p.skipLoopHeader(label)
+				}
+			}
+		}
+	}
+
+	s := &ast.ForStmt{Token: tk, Init: init, Post: post}
+	if cond != nil {
+		c, ok := cond.(*ast.ExprStmt)
+		if !ok {
+			p.errorf(BadStmt, condToken.Pos, "expected for loop condition, got %s", cond)
+			return p.skipLoopHeader(label)
+		}
+		s.Cond = c.Expr
+	}
+	if s.Body = p.parseLoopBody(label); s.Body == nil {
+		return nil
+	}
 	return s
 }
 
-func (p *Parser) parseBlockStmt() *ast.BlockStmt {
-	block := &ast.BlockStmt{Token: p.curToken}
+// parseRangeClause 解析 range 子句,调用时当前 Token 为 range
+func (p *Parser) parseRangeClause(tk *token.Token, lhs []ast.Expr, starts []*token.Token) ast.Stmt {
+	if len(lhs) > 2 {
+		p.errorf(BadStmt, starts[2].Pos, "range clause permits at most two iteration variables")
+		return nil
+	}
+	if !p.checkAssignTargets(tk, lhs, starts) {
+		return nil
+	}
+	s := &ast.RangeStmt{Key: lhs[0], Tok: tk}
+	if len(lhs) == 2 {
+		s.Value = lhs[1]
+	}
 	p.nextToken()
+	s.X = p.parseExpr(token.LowestPrec)
+	return s
+}
+
+func (p *Parser) parseRangeStmt(tk *token.Token, label *ast.Identifier, s *ast.RangeStmt) ast.Stmt {
+	if s.X == nil {
+		return nil
+	}
+	s.Token = tk
+	if s.Body = p.parseLoopBody(label); s.Body == nil {
+		return nil
+	}
+	return s
+}
+
+// skipLoopHeader 跳过出错的循环头,若循环体与其在同一行则继续解析循环体以报告其中的错误
+func (p *Parser) skipLoopHeader(label *ast.Identifier) ast.Stmt {
+	base := p.braces
+	for !p.assertionPeekToken(token.EOF) && !(p.depth() == base && p.assertionPeekToken(token.LBRACE)) {
+		if p.assertionPeekToken(token.SEMICOLON) && p.peekToken.Value == "\n" {
+			return nil
+		}
+		p.nextToken()
+	}
+	if p.assertionPeekToken(token.LBRACE) {
+		p.parseLoopBody(label)
+	}
+	return nil
+}
+
+// parseLoopBody 解析循环体,循环体中可以使用 break 与 continue
+func (p *Parser) parseLoopBody(label *ast.Identifier) *ast.BlockStmt {
+	if !p.forecastNextPeek(token.LBRACE) {
+		return nil
+	}
+	target := branchTarget{loop: true}
+	if label != nil {
+		target.label = label.Value
+	}
+	p.branches = append(p.branches, target)
+	body := p.parseBlockStmt()
+	p.branches = p.branches[:len(p.branches)-1]
+	return body
+}
+
+// parseSwitchStmt 解析 switch 语句,初始化语句与表达式都可以省略
+func (p *Parser) parseSwitchStmt(label *ast.Identifier) ast.Stmt {
+	s := &ast.SwitchStmt{Token: p.curToken}
+
+	if !p.assertionPeekToken(token.LBRACE) {
+		p.nextToken()
+		var tag ast.Stmt
+		var tagToken *token.Token
+		if !p.assertionCurToken(token.SEMICOLON) {
+			tagToken = p.curToken
+			if tag = p.parseSimpleStmt(false); tag == nil {
+				return nil
+			}
+			if p.assertionPeekToken(token.SEMICOLON) {
+				p.nextToken()
+			}
+		}
+		if p.assertionCurToken(token.SEMICOLON) {
+			s.Init, tag = tag, nil
+			if !p.assertionPeekToken(token.LBRACE) {
+				p.nextToken()
+				tagToken = p.curToken
+				if tag = p.parseSimpleStmt(false); tag == nil {
+					return nil
+				}
+			}
+		}
+		if tag != nil {
+			e, ok := tag.(*ast.ExprStmt)
+			if !ok {
+				p.errorf(BadStmt, tagToken.Pos, "expected switch expression, got %s", tag)
+				return nil
+			}
+			s.Tag = e.Expr
+		}
+	}
+
+	if !p.forecastNextPeek(token.LBRACE) {
+		return nil
+	}
+	target := branchTarget{}
+	if label != nil {
+		target.label = label.Value
+	}
+	p.branches = append(p.branches, target)
+	body, ok := p.parseCaseClauses()
+	p.branches = p.branches[:len(p.branches)-1]
+	if !ok {
+		return nil
+	}
+	s.Body = body
+	return s
+}
+
+// parseCaseClauses 解析 switch 的语句块,调用时当前 Token 为 {
+func (p *Parser) parseCaseClauses() ([]*ast.CaseClause, bool) {
+	var clauses []*ast.CaseClause
+	var hasDefault bool
+	ok := true
+
+	p.nextToken()
+	base := p.braces
 	for !p.assertionCurToken(token.RBRACE) && !p.assertionCurToken(token.EOF) {
-		stmt := p.parseStmt()
-		if stmt != nil {
-			block.Stmts = append(block.Stmts, stmt)
+		clause := &ast.CaseClause{Token: p.curToken}
+		headerOk := true
+		switch p.curToken.Type {
+		case token.CASE:
+			p.nextToken()
+			if clause.List, _ = p.parseExprList(); clause.List == nil {
+				headerOk = false
+			}
+		case token.DEFAULT:
+			if hasDefault {
+				p.errorf(BadStmt, p.curToken.Pos, "multiple defaults in switch")
+				headerOk = false
+			}
+			hasDefault = true
+		default:
+			p.unexpectedf(p.curToken, []token.Type{token.CASE, token.DEFAULT, token.RBRACE}, "expected case or default or }, got %s", p.curToken.Type)
+			headerOk = false
 		}
+		if headerOk && !p.forecastNextPeek(token.COLON) {
+			headerOk = false
+		}
+
+		if !headerOk {
+			// 跳过出错的 case 头部,仍然解析它的语句以便报告其中的错误
+			ok = false
+			for !p.assertionCurToken(token.EOF) && !p.assertionPeekToken(token.EOF) {
+				if p.depth() == base && (p.assertionCurToken(token.COLON) || p.assertionCurToken(token.RBRACE) ||
+					p.assertionPeekToken(token.CASE) || p.assertionPeekToken(token.DEFAULT) || p.assertionPeekToken(token.RBRACE)) {
+					break
+				}
+				p.nextToken()
+			}
+			if p.assertionCurToken(token.RBRACE) && p.depth() == base {
+				break
+			}
+		}
+
+		p.nextToken()
+		clause.Body = p.parseStmtList(p.parseCaseStmt, token.CASE, token.DEFAULT, token.RBRACE)
+		clauses = append(clauses, clause)
+	}
+	if !p.assertionCurToken(token.RBRACE) {
+		p.unexpectedf(p.curToken, []token.Type{token.RBRACE}, "expected token %s, got %s", token.RBRACE, p.curToken.Type)
+		return nil, false
+	}
+
+	// fallthrough 只能是 case 的最后一条语句,并且不能出现在最后一个 case 中
+	for i, clause := range clauses {
+		for j, stmt := range clause.Body {
+			b, isBranch := stmt.(*ast.BranchStmt)
+			if !isBranch || b.Token.Type != token.FALLTHROUGH {
+				continue
+			}
+			switch {
+			case j != len(clause.Body)-1:
+				p.errorf(BadBranch, b.Token.Pos, "fallthrough statement out of place")
+				ok = false
+			case i == len(clauses)-1:
+				p.errorf(BadBranch, b.Token.Pos, "cannot fallthrough final case in switch")
+				ok = false
+			}
+		}
+	}
+	return clauses, ok
+}
+
+// parseCaseStmt 解析 case 中的语句, fallthrough 的位置由 parseCaseClauses 检查
+func (p *Parser) parseCaseStmt() ast.Stmt {
+	if p.assertionCurToken(token.FALLTHROUGH) {
+		return &ast.BranchStmt{Token: p.curToken}
+	}
+	return p.parseStmt()
+}
+
+func (p *Parser) parseBranchStmt() ast.Stmt {
+	s := &ast.BranchStmt{Token: p.curToken}
+	if p.assertionPeekToken(token.IDENT) {
 		p.nextToken()
+		s.Label = &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}
+	}
+	if !p.checkBranch(s) {
+		return nil
+	}
+	return s
+}
+
+// checkBranch break 只能出现在循环或 switch 中, continue 只能出现在循环中,
+// 带标签时标签必须属于外层的循环或 switch
+func (p *Parser) checkBranch(s *ast.BranchStmt) bool {
+	isContinue := s.Token.Type == token.CONTINUE
+	for i := len(p.branches) - 1; i >= 0; i-- {
+		b := p.branches[i]
+		if s.Label == nil {
+			if b.loop || !isContinue {
+				return true
+			}
+			continue
+		}
+		if b.label == s.Label.Value {
+			if isContinue && !b.loop {
+				p.errorf(BadBranch, s.Label.Token.Pos, "invalid continue label %s", s.Label.Value)
+				return false
+			}
+			return true
+		}
+	}
+
+	switch {
+	case s.Label != nil:
+		p.errorf(BadBranch, s.Label.Token.Pos, "%s label not defined: %s", s.TokenValue(), s.Label.Value)
+	case isContinue:
+		p.errorf(BadBranch, s.Token.Pos, "continue is not in a loop")
+	default:
+		p.errorf(BadBranch, s.Token.Pos, "break is not in a loop or switch")
+	}
+	return false
+}
+
+func (p *Parser) parseLabeledStmt() ast.Stmt {
+	s := &ast.LabeledStmt{Token: p.curToken, Label: &ast.Identifier{Token: p.curToken, Value: p.curToken.Value}}
+	p.nextToken()
+	p.nextToken()
+
+	switch p.curToken.Type {
+	case token.FOR:
+		s.Stmt = p.parseForStmt(s.Label)
+	case token.SWITCH:
+		s.Stmt = p.parseSwitchStmt(s.Label)
+	default:
+		s.Stmt = p.parseStmt()
+	}
+	if s.Stmt == nil {
+		return nil
+	}
+	return s
+}
+
+// parseBlockStmt 调用时当前 Token 为 {,结束时为 };缺少 } 时返回 nil
+func (p *Parser) parseBlockStmt() *ast.BlockStmt {
+	block := &ast.BlockStmt{Token: p.curToken}
+	p.nextToken()
+	block.Stmts = p.parseStmtList(p.parseStmt, token.RBRACE)
+	if !p.assertionCurToken(token.RBRACE) {
+		p.unexpectedf(p.curToken, []token.Type{token.RBRACE}, "expected token %s, got %s", token.RBRACE, p.curToken.Type)
+		return nil
 	}
 	return block
 }
@@ -410,9 +1350,14 @@ type Registry func(p *Parser)
 func defaultRegister(p *Parser) {
 	p.registerPrefix(token.IDENT, p.parseIdentifierExpr)
 	p.registerPrefix(token.INT, p.parseIntegerExpr)
+	p.registerPrefix(token.FLOAT, p.parseFloatExpr)
+	p.registerPrefix(token.IMAG, p.parseImagExpr)
 	p.registerPrefix(token.STRING, p.parseStringExpr)
+	p.registerPrefix(token.STRING_HEAD, p.parseInterpolatedStringExpr)
+	p.registerPrefix(token.CHAR, p.parseCharExpr)
 	p.registerPrefix(token.SUB, p.parsePrefixExpr)
 	p.registerPrefix(token.NOT, p.parsePrefixExpr)
+	p.registerPrefix(token.XOR, p.parsePrefixExpr)
 	p.registerPrefix(token.TRUE, p.parseBooleanExpr)
 	p.registerPrefix(token.FALSE, p.parseBooleanExpr)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpr)
@@ -426,11 +1371,21 @@ func defaultRegister(p *Parser) {
 	p.registerInfix(token.SUB, p.parseInfixExpr)
 	p.registerInfix(token.QUO, p.parseInfixExpr)
 	p.registerInfix(token.MUL, p.parseInfixExpr)
+	p.registerInfix(token.REM, p.parseInfixExpr)
+	p.registerInfix(token.AND, p.parseInfixExpr)
+	p.registerInfix(token.OR, p.parseInfixExpr)
+	p.registerInfix(token.XOR, p.parseInfixExpr)
+	p.registerInfix(token.SHL, p.parseInfixExpr)
+	p.registerInfix(token.SHR, p.parseInfixExpr)
+	p.registerInfix(token.AND_NOT, p.parseInfixExpr)
+	p.registerInfix(token.LAND, p.parseInfixExpr)
+	p.registerInfix(token.LOR, p.parseInfixExpr)
 	p.registerInfix(token.EQL, p.parseInfixExpr)
-	p.registerInfix(token.ASSIGN, p.parseInfixExpr)
 	p.registerInfix(token.NEQ, p.parseInfixExpr)
 	p.registerInfix(token.LSS, p.parseInfixExpr)
 	p.registerInfix(token.GTR, p.parseInfixExpr)
+	p.registerInfix(token.LEQ, p.parseInfixExpr)
+	p.registerInfix(token.GEQ, p.parseInfixExpr)
 	p.registerInfix(token.LPAREN, p.parseCallExpr)
 	p.registerInfix(token.LBRACK, p.parseIndexExpr)
 }
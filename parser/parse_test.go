@@ -3,10 +3,13 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/songzhibin97/mini-interpreter/ast"
 	"github.com/songzhibin97/mini-interpreter/lexer"
+	"github.com/songzhibin97/mini-interpreter/token"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -140,6 +143,105 @@ func TestParser_parseInteger(t *testing.T) {
 	testInteger(t, stmt.Expr, int64(10))
 }
 
+func TestParser_parseIntegerLiteral(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect int64
+	}{
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"9223372036854775807", 9223372036854775807},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		stmt, ok := v.Stmts[0].(*ast.ExprStmt)
+		assert.Equal(t, ok, true)
+		integer, ok := stmt.Expr.(*ast.Integer)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, integer.Value, tt.expect)
+	}
+
+	p := NewParser(lexer.NewLexer("var a = 1\nvar b = 9223372036854775808"))
+	p.ParseProgram()
+	assert.Equal(t, []string{"2:9: integer literal 9223372036854775808 overflows int64"}, p.Errors())
+
+	// 词法分析器已经报告的错误不再重复报告
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"1__0", "1:3: '_' must separate successive digits"},
+		{"0b102", "1:5: invalid digit '2' in binary literal"},
+		{"09", "1:2: invalid digit '9' in octal literal"},
+		{"0x", "1:1: hexadecimal literal has no digits"},
+		{"1e", "1:1: exponent has no digits"},
+		{"0xi", "1:1: hexadecimal literal has no digits"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Equal(t, []string{tt.err}, p.Errors(), tt.input)
+	}
+}
+
+func TestParser_parseFloat(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect float64
+	}{
+		{"1.5", 1.5},
+		{".25", 0.25},
+		{"1e-9", 1e-9},
+		{"2.5e3", 2500},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		assert.Equal(t, len(v.Stmts), 1)
+		stmt, ok := v.Stmts[0].(*ast.ExprStmt)
+		assert.Equal(t, ok, true)
+		f, ok := stmt.Expr.(*ast.Float)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, f.Value, tt.expect)
+		assert.Equal(t, f.TokenValue(), tt.input)
+	}
+}
+
+func TestParser_parseImag(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect complex128
+	}{
+		{"2i", 2i},
+		{"1.5i", 1.5i},
+		{"1e3i", 1000i},
+		{"0x10i", 16i},
+		{"09i", 9i},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		stmt, ok := v.Stmts[0].(*ast.ExprStmt)
+		assert.Equal(t, ok, true)
+		c, ok := stmt.Expr.(*ast.Imag)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, c.Value, tt.expect)
+	}
+}
+
 func TestParser_parseString(t *testing.T) {
 	input := `"hello"`
 	p := NewParser(lexer.NewLexer(input))
@@ -155,6 +257,72 @@ func TestParser_parseString(t *testing.T) {
 	assert.Equal(t, integer.Value, "hello")
 }
 
+func TestParser_parseChar(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect rune
+	}{
+		{`'a'`, 'a'},
+		{`'\n'`, '\n'},
+		{`'世'`, '世'},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		assert.Equal(t, len(v.Stmts), 1)
+		stmt, ok := v.Stmts[0].(*ast.ExprStmt)
+		assert.Equal(t, ok, true)
+		c, ok := stmt.Expr.(*ast.Char)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, c.Value, tt.expect)
+		assert.Equal(t, c.String(), tt.input)
+	}
+}
+
+func TestParser_parseInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input  string
+		parts  int
+		expect string
+	}{
+		{`"a${b}c"`, 3, `"a${b}c"`},
+		{`"${a + 1} and ${len([1, 2])}!"`, 5, `"${(a + 1)} and ${len([1, 2])}!"`},
+		{`"x ${"in ${y}ner"} \${z}"`, 3, `"x ${"in ${y}ner"} \${z}"`},
+		{`"${ {"k": 1}["k"] }"`, 3, `"${({"k":1}["k"])}"`},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		assert.Equal(t, len(v.Stmts), 1)
+		stmt, ok := v.Stmts[0].(*ast.ExprStmt)
+		assert.Equal(t, ok, true)
+		s, ok := stmt.Expr.(*ast.InterpolatedString)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, tt.parts, len(s.Parts))
+		assert.Equal(t, tt.expect, s.String())
+	}
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{`"a${b c}"`, "1:7: expected token STRING_TAIL, got IDENT"},
+		{`"a${}"`, "1:5: no prefix parse function for STRING_TAIL found"},
+		{`"a${b`, "1:6: string literal not terminated"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err)
+	}
+}
+
 func TestParser_parseArray(t *testing.T) {
 	input := `[]`
 	p := NewParser(lexer.NewLexer(input))
@@ -289,6 +457,8 @@ func TestParser_parsePrefixExpr(t *testing.T) {
 		{"-a", "-", "a"},
 		{"!true", "!", true},
 		{"!false", "!", false},
+		{"^10", "^", 10},
+		{"^a", "^", "a"},
 	}
 	for _, tt := range tests {
 		p := NewParser(lexer.NewLexer(tt.input))
@@ -342,6 +512,15 @@ func TestParser_parseInfixExpr(t *testing.T) {
 		{"1 < 1", 1, "<", 1},
 		{"1 == 1", 1, "==", 1},
 		{"1 != 1", 1, "!=", 1},
+		{"1 <= 1", 1, "<=", 1},
+		{"1 >= 1", 1, ">=", 1},
+		{"1 % 1", 1, "%", 1},
+		{"1 & 1", 1, "&", 1},
+		{"1 | 1", 1, "|", 1},
+		{"1 ^ 1", 1, "^", 1},
+		{"1 << 1", 1, "<<", 1},
+		{"1 >> 1", 1, ">>", 1},
+		{"1 &^ 1", 1, "&^", 1},
 		{"a + b", "a", "+", "b"},
 		{"a - b", "a", "-", "b"},
 		{"a * b", "a", "*", "b"},
@@ -353,6 +532,8 @@ func TestParser_parseInfixExpr(t *testing.T) {
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
+		{"true && false", true, "&&", false},
+		{"true || false", true, "||", false},
 	}
 
 	for _, test := range tests {
@@ -469,6 +650,30 @@ func TestParser_operator(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c",
+			"((a && b) || c)",
+		},
+		{
+			"a < b && b <= c || d >= e",
+			"(((a < b) && (b <= c)) || (d >= e))",
+		},
+		{
+			"a + b % c << d",
+			"(a + ((b % c) << d))",
+		},
+		{
+			"a | b ^ c & d &^ e >> f",
+			"((a | b) ^ (((c & d) &^ e) >> f))",
+		},
+		{
+			"^a & -b",
+			"((^a) & (-b))",
+		},
 	}
 	for _, tt := range tests {
 		p := NewParser(lexer.NewLexer(tt.input))
@@ -520,6 +725,56 @@ func TestParser_parseIfExpr(t *testing.T) {
 	consequence, ok := expr.Consequence.Stmts[0].(*ast.ExprStmt)
 	assert.Equal(t, ok, true)
 	testIdentifier(t, consequence.Expr, "x")
+
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"if x < y { x }", "if(x < y) x"},
+		{"if (x) { y } else { z }", "ifx yelse z"},
+		{"if v := f(); v > 0 { v }", "if v := f(); (v > 0) v"},
+		{"if ; x { y }", "ifx y"},
+		{"if x { a } else if y { b } else if (z) { c } else { d }", "ifx aelse ify belse ifz celse d"},
+		{"if x { a } else if v := f(); v { b }", "ifx aelse if v := f(); v b"},
+		{"if x {\n\ta\n} else if y {\n\tb\n}", "ifx aelse ify b"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p = NewParser(lexer.NewLexer("if v := 1; v { a } else if w := 2; w { b } else { c }"))
+	expr = p.ParseProgram().Stmts[0].(*ast.ExprStmt).Expr.(*ast.IfExpr)
+	assert.Equal(t, "v := 1", expr.Init.String())
+	elseIf, ok := expr.Alternative.Stmts[0].(*ast.ExprStmt).Expr.(*ast.IfExpr)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "w := 2", elseIf.Init.String())
+	testIdentifier(t, elseIf.Condition, "w")
+	assert.Equal(t, 1, len(elseIf.Alternative.Stmts))
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"if { x }", "1:4: missing condition in if statement"},
+		{"if { }", "1:4: missing condition in if statement"},
+		{"if v := 1; { x }", "1:12: missing condition in if statement"},
+		{"if v := 1 { x }", "1:4: expected if condition, got v := 1"},
+		{"if x := 1 { }", "1:4: expected if condition, got x := 1"},
+		{"if x\n{ y }", "1:5: expected token {, got ;"},
+		{"if x { y } else z", "1:17: expected token {, got IDENT"},
+		{"if x { y } else if { z }", "1:20: missing condition in if statement"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Equal(t, []string{tt.err}, p.Errors(), tt.input)
+	}
 }
 
 func TestParser_parseFuncExpr(t *testing.T) {
@@ -544,6 +799,40 @@ func TestParser_parseFuncExpr(t *testing.T) {
 	testInfixExpr(t, bodyStmt.Expr, "x", "+", "y")
 }
 
+func TestParser_parseAnonymousFuncExpr(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"func(x, y) { x + y }", "func(x, y) (x + y)"},
+		{"func() {}", "func() "},
+		{"f := func(x) { x }", "f := func(x) x"},
+		{"apply(func(x) { x * 2 }, 3)", "apply(func(x) (x * 2), 3)"},
+		{"[func() { 1 }, func() { 2 }]", "[func() 1, func() 2]"},
+		{"func(x) { x }(1)", "func(x) x(1)"},
+		{"func() { func() { 1 } }", "func() func() 1"},
+		{"fs[0]()", "(fs[0])()"},
+		{"adder(2)(3)", "adder(2)(3)"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("func(a) { a }"))
+	v := p.ParseProgram()
+	expr, ok := v.Stmts[0].(*ast.ExprStmt).Expr.(*ast.FuncExpr)
+	assert.Equal(t, true, ok)
+	assert.Nil(t, expr.Name)
+	assert.Equal(t, 1, len(expr.Params))
+	testIdentifier(t, expr.Params[0], "a")
+}
+
 func TestParser_parseFuncParams(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -552,6 +841,9 @@ func TestParser_parseFuncParams(t *testing.T) {
 		{"func a () {}", []string{}},
 		{"func a (x) {}", []string{"x"}},
 		{"func a (x, y, z) {}", []string{"x", "y", "z"}},
+		{"func a (x, y,) {}", []string{"x", "y"}},
+		{"func a (xs...) {}", []string{"xs"}},
+		{"func a (x, xs...,) {}", []string{"x", "xs"}},
 	}
 	for _, tt := range tests {
 		p := NewParser(lexer.NewLexer(tt.input))
@@ -570,6 +862,132 @@ func TestParser_parseFuncParams(t *testing.T) {
 	}
 }
 
+func TestParser_parseVariadic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expect   string
+		variadic bool
+	}{
+		{"func sum(nums...) { nums }", "func sum (nums...) nums", true},
+		{"func(a, rest...) {}", "func(a, rest...) ", true},
+		{"func f(a, b) {}", "func f (a, b) ", false},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		fn, ok := v.Stmts[0].(*ast.ExprStmt).Expr.(*ast.FuncExpr)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, tt.variadic, fn.Variadic)
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	spreads := []struct {
+		input  string
+		expect string
+	}{
+		{"f(xs...)", "f(xs...)"},
+		{"f(1, xs..., 2)", "f(1, xs..., 2)"},
+		{"f([1, 2]...)", "f([1, 2]...)"},
+		{"[a..., 4]", "[a..., 4]"},
+		{"[a + b..., g(c)...,]", "[(a + b)..., g(c)...]"},
+	}
+	for _, tt := range spreads {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("f(1, xs...)"))
+	call := p.ParseProgram().Stmts[0].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	spread, ok := call.Args[1].(*ast.SpreadExpr)
+	assert.Equal(t, true, ok)
+	testIdentifier(t, spread.Value, "xs")
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"func f(a..., b) {}", "1:8: can only use ... with final parameter in list"},
+		{"macro m(a...) {}", "1:9: macro cannot have variadic parameters"},
+		{"xs...", "1:3: expected ; or newline after statement, got ..."},
+		{"{1: xs...}", "1:7: expected token ,, got ..."},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err, tt.input)
+	}
+
+	// 参数有误的调用不会出现在结果中,解析从下一条语句继续
+	for _, input := range []string{"f(a: 1, 2)\nx := 1", "f(a: 1, a: 2)\nx := 1"} {
+		p := NewParser(lexer.NewLexer(input))
+		program := p.ParseProgram()
+		assert.Equal(t, 1, len(p.Errors()), input)
+		assert.Equal(t, "x := 1", program.String(), input)
+	}
+}
+
+func TestParser_parseDefaultsAndKeywords(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"func connect(host, port = 8080) {}", "func connect (host, port = 8080) "},
+		{"func(a = 1, b = a + 1, rest...) {}", "func(a = 1, b = (a + 1), rest...) "},
+		{`connect("db", port: 5432)`, `connect("db", port: 5432)`},
+		{"f(a: 1, b: x + 1,)", "f(a: 1, b: (x + 1))"},
+		{"f(xs..., a: 1)", "f(xs..., a: 1)"},
+		{"f(g(a: 1), b: {1: 2})", "f(g(a: 1), b: {1:2})"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("func f(a, b = 2) {}"))
+	fn := p.ParseProgram().Stmts[0].(*ast.ExprStmt).Expr.(*ast.FuncExpr)
+	assert.Equal(t, 2, len(fn.Defaults))
+	assert.Nil(t, fn.Defaults[0])
+	testExpr(t, fn.Defaults[1], 2)
+
+	p = NewParser(lexer.NewLexer("func f(a, b) {}"))
+	fn = p.ParseProgram().Stmts[0].(*ast.ExprStmt).Expr.(*ast.FuncExpr)
+	assert.Nil(t, fn.Defaults)
+
+	p = NewParser(lexer.NewLexer("f(1, b: 2)"))
+	call := p.ParseProgram().Stmts[0].(*ast.ExprStmt).Expr.(*ast.CallExpr)
+	assert.Equal(t, 1, len(call.Args))
+	assert.Equal(t, 1, len(call.Keywords))
+	testIdentifier(t, call.Keywords[0].Name, "b")
+	testExpr(t, call.Keywords[0].Value, 2)
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"func f(a = 1, b) {}", "1:15: parameter b without default follows parameter with default"},
+		{"func f(a... = 1) {}", "1:8: variadic parameter a cannot have default value"},
+		{"macro m(a = 1) {}", "1:9: macro cannot have default parameter values"},
+		{"f(a: 1, 2)", "1:9: positional argument follows keyword argument"},
+		{"f(a: 1, a: 2)", "1:9: duplicate keyword argument a"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err, tt.input)
+	}
+}
+
 func TestParser_parseCallExpr(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5)"
 	p := NewParser(lexer.NewLexer(input))
@@ -628,3 +1046,406 @@ func TestParser_parseCallArgsExpr(t *testing.T) {
 		}
 	}
 }
+
+func TestParser_errorPosition(t *testing.T) {
+	input := "var a = 1\nadd(a, 2"
+	fset := token.NewFileSet()
+	p := NewParser(lexer.NewLexer(input, lexer.WithFile(fset.AddFile("main.mini", len(input)))))
+	p.ParseProgram()
+	assert.Equal(t, []string{"main.mini:2:9: expected token ), got EOF"}, p.Errors())
+
+	p = NewParser(lexer.NewLexer("var = 1"))
+	p.ParseProgram()
+	assert.Equal(t, "1:5: expected token IDENT, got =", p.Errors()[0])
+
+	p = NewParser(lexer.NewLexer("var a = @1\nvar b = 2 $"))
+	v := p.ParseProgram()
+	assert.Equal(t, []string{
+		"1:9: illegal character U+0040 '@'",
+		"2:11: illegal character U+0024 '$'",
+	}, p.Errors())
+	assert.Equal(t, 2, len(v.Stmts))
+}
+
+func TestParser_comment(t *testing.T) {
+	input := `
+	// add returns the sum
+	func add(a, b) { /* inline */ a + b }
+	add(1, 2) // call`
+	p := NewParser(lexer.NewLexer(input))
+	v := p.ParseProgram()
+	for _, s := range p.Errors() {
+		t.Errorf("parser error: %s", s)
+	}
+	assert.Equal(t, len(v.Stmts), 2)
+
+	p = NewParser(lexer.NewLexer("1 + /* oops"))
+	p.ParseProgram()
+	assert.Contains(t, p.Errors(), "1:5: comment not terminated")
+}
+
+func TestParser_semicolon(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"var a = 1; var b = 2", "var a = 1var b = 2"},
+		{"var a = 1\nvar b = 2\n", "var a = 1var b = 2"},
+		{";; a;; b;", "ab"},
+		{"add(\n\t1,\n\t2,\n)", "add(1, 2)"},
+		{"[1, 2, 3,]", "[1, 2, 3]"},
+		{"{\n\t1: 2,\n}", "{1:2}"},
+		{"func f(a, b,) {\n\treturn\n}", "func f (a, b) return "},
+		{"func f() { a; return; }", "func f () areturn "},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("var a = 1 var b = 2"))
+	p.ParseProgram()
+	assert.Equal(t, []string{"1:11: expected ; or newline after statement, got var"}, p.Errors())
+
+	p = NewParser(lexer.NewLexer("[1,\n2\n]"))
+	p.ParseProgram()
+	assert.Equal(t, "2:2: expected token ], got ;", p.Errors()[0])
+}
+
+func TestParser_parseAssignStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"x = 1", "x = 1"},
+		{"x := 1", "x := 1"},
+		{"a, b := 1, 2", "a, b := 1, 2"},
+		{"a, b = b, a", "a, b = b, a"},
+		{"a[0], m[k] = f(1, 2), -x", "(a[0]), (m[k]) = f(1, 2), (-x)"},
+		{"a[0] = b[1] + 2", "(a[0]) = ((b[1]) + 2)"},
+		{`m["k"] = {"v": 1}`, `(m["k"]) = {"v":1}`},
+		{"x += 1", "x += 1"},
+		{"x <<= 2", "x <<= 2"},
+		{"x &^= y + 1", "x &^= (y + 1)"},
+		{"arr[i] *= 3", "(arr[i]) *= 3"},
+		{`m["k"] -= 1`, `(m["k"]) -= 1`},
+		{"x++", "x++"},
+		{"a[0]--", "(a[0])--"},
+		{"func f() { x++ }", "func f () x++"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("parser error: %s", s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("x += 1"))
+	stmt, ok := p.ParseProgram().Stmts[0].(*ast.AssignStmt)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "+=", stmt.TokenValue())
+	testExpr(t, stmt.Targets[0], "x")
+	testExpr(t, stmt.Values[0], 1)
+
+	p = NewParser(lexer.NewLexer("x++"))
+	incDec, ok := p.ParseProgram().Stmts[0].(*ast.IncDecStmt)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "++", incDec.TokenValue())
+	testExpr(t, incDec.Target, "x")
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"1 += 2", "1:1: cannot assign to 1"},
+		{`"a" = 2`, `1:1: cannot assign to "a"`},
+		{"a, b := 1", "1:6: assignment mismatch: 2 variables but 1 value"},
+		{"a = 1, 2", "1:3: assignment mismatch: 1 variable but 2 values"},
+		{"a, b[0] := 1, 2", "1:4: non-name (b[0]) on left side of :="},
+		{"a, 1 = 1, 2", "1:4: cannot assign to 1"},
+		{"a, b += 1, 2", "1:6: assignment operation += requires single-valued expressions"},
+		{"a, b", "1:5: expected := or = after expression list, got EOF"},
+		{"f() ++", "1:1: cannot assign to f()"},
+		{"x + 1 -= 2", "1:1: cannot assign to (x + 1)"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		assert.Equal(t, []string{tt.err}, p.Errors())
+		assert.Equal(t, 0, len(v.Stmts))
+	}
+}
+
+func TestParser_parseConstStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"const A = 1", "const A = 1"},
+		{"const A = iota", "const A = iota"},
+		{"const (A = iota; B; C)", "const (A = iota; B; C)"},
+		{"const (\n\tA = 1 << iota\n\tB\n\n\tC = \"c\"\n\tD\n)", `const (A = (1 << iota); B; C = "c"; D)`},
+		{"const ()", "const ()"},
+		{"const (A = 1;)", "const (A = 1)"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("const (\n\tA = iota * 10\n\tB\n\tC\n)"))
+	stmt, ok := p.ParseProgram().Stmts[0].(*ast.ConstStmt)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, true, stmt.Grouped)
+	assert.Equal(t, 3, len(stmt.Specs))
+	for i, name := range []string{"A", "B", "C"} {
+		testIdentifier(t, stmt.Specs[i].Name, name)
+		testInfixExpr(t, stmt.Specs[i].Value, "iota", "*", 10)
+		assert.Equal(t, i, stmt.Specs[i].Iota)
+		assert.Equal(t, i != 0, stmt.Specs[i].Implicit)
+	}
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"const A", "1:7: missing init expr for const declaration"},
+		{"const (A; B = 1)", "1:8: missing init expr for const declaration"},
+		{"const 1 = 2", "1:7: expected token IDENT, got INT"},
+		{"const (A = 1 B = 2)", "1:14: expected token ;, got IDENT"},
+		{"const (A = 1", "1:13: expected token ), got EOF"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err, tt.input)
+	}
+}
+
+func TestParser_parseForStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"for { x }", "for x"},
+		{"for i < 10 { i++ }", "for (i < 10) i++"},
+		{"for i := 0; i < 10; i++ { x += i }", "for i := 0; (i < 10); i++ x += i"},
+		{"for ; ; { break }", "for break"},
+		{"for i := 0; ; { continue }", "for i := 0; ;  continue"},
+		{"for i, v := range arr { x += v }", "for i, v := range arr x += v"},
+		{"for k := range {1: 2} {}", "for k := range {1:2} "},
+		{"for i, a[0] = range [1, 2] {}", "for i, (a[0]) = range [1, 2] "},
+		{"for range arr { x++ }", "for range arr x++"},
+		{"outer: for { for { break outer } }", "outer: for for break outer"},
+		{"L: for { continue L }", "L: for continue L"},
+		{"for {\n\tif (x) { break }\n\tx++\n}", "for ifx breakx++"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("for i := 0; i < 3; i++ {}"))
+	stmt, ok := p.ParseProgram().Stmts[0].(*ast.ForStmt)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "i := 0", stmt.Init.String())
+	testInfixExpr(t, stmt.Cond, "i", "<", 3)
+	assert.Equal(t, "i++", stmt.Post.String())
+
+	p = NewParser(lexer.NewLexer("for k, v := range m {}"))
+	rangeStmt, ok := p.ParseProgram().Stmts[0].(*ast.RangeStmt)
+	assert.Equal(t, true, ok)
+	testExpr(t, rangeStmt.Key, "k")
+	testExpr(t, rangeStmt.Value, "v")
+	testExpr(t, rangeStmt.X, "m")
+	assert.Equal(t, token.DEFINE, rangeStmt.Tok.Type)
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"break", "1:1: break is not in a loop or switch"},
+		{"if (x) { continue }", "1:10: continue is not in a loop"},
+		{"for { func f() { break } }", "1:18: break is not in a loop or switch"},
+		{"for { break L }", "1:13: break label not defined: L"},
+		{"L: x := 1; for { continue L }", "1:27: continue label not defined: L"},
+		{"for a, b, c := range x {}", "1:11: range clause permits at most two iteration variables"},
+		{"for x := 1 {}", "1:5: expected for loop condition, got x := 1"},
+		{"for i := 0; i < 3 {}", "1:19: expected token ;, got {"},
+		{"for 1 := range x {}", "1:5: non-name 1 on left side of :="},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err, tt.input)
+	}
+}
+
+func TestParser_parseSwitchStmt(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"switch {}", "switch "},
+		{"switch x { case 1, 2: y; default: z }", "switch x case 1, 2: y default: z"},
+		{"switch { case x > 3: a; b }", "switch case (x > 3): ab"},
+		{"switch x := f(); x { default: }", "switch x := f(); x default: "},
+		{"switch x := 1; { case x == 1: }", "switch x := 1; case (x == 1): "},
+		{"switch x {\ncase 1:\n\ty\n\tfallthrough\ncase 2:\n}", "switch x case 1: yfallthrough case 2: "},
+		{"switch x { case 1: break }", "switch x case 1: break"},
+		{"L: switch { default: break L }", "L: switch default: break L"},
+		{"for { switch { case true: continue } }", "for switch case true: continue"},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		for _, s := range p.Errors() {
+			t.Errorf("%q: parser error: %s", tt.input, s)
+		}
+		assert.Equal(t, 1, len(v.Stmts))
+		assert.Equal(t, tt.expect, v.String())
+	}
+
+	p := NewParser(lexer.NewLexer("switch x { case 1, 2: y; default: }"))
+	stmt, ok := p.ParseProgram().Stmts[0].(*ast.SwitchStmt)
+	assert.Equal(t, true, ok)
+	testExpr(t, stmt.Tag, "x")
+	assert.Equal(t, 2, len(stmt.Body))
+	assert.Equal(t, 2, len(stmt.Body[0].List))
+	testExpr(t, stmt.Body[0].List[1], 2)
+	assert.Equal(t, 1, len(stmt.Body[0].Body))
+	assert.Nil(t, stmt.Body[1].List)
+
+	errs := []struct {
+		input string
+		err   string
+	}{
+		{"switch { default: default: }", "1:19: multiple defaults in switch"},
+		{"switch { x }", "1:10: expected case or default or }, got IDENT"},
+		{"switch { case 1 }", "1:17: expected token :, got }"},
+		{"switch x { case 1: fallthrough }", "1:20: cannot fallthrough final case in switch"},
+		{"switch x { case 1: fallthrough; y; case 2: }", "1:20: fallthrough statement out of place"},
+		{"switch x { case 1: if (y) { fallthrough }; case 2: }", "1:29: fallthrough statement out of place"},
+		{"fallthrough", "1:1: fallthrough statement out of place"},
+		{"switch { case true: continue }", "1:21: continue is not in a loop"},
+		{"L: switch { case true: for { continue L } }", "1:39: invalid continue label L"},
+		{"switch x := 1 {}", "1:8: expected switch expression, got x := 1"},
+	}
+	for _, tt := range errs {
+		p := NewParser(lexer.NewLexer(tt.input))
+		p.ParseProgram()
+		assert.Contains(t, p.Errors(), tt.err, tt.input)
+	}
+}
+
+func TestParser_errorRecovery(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+		errs   []string
+	}{
+		{"x := 1 +\ny := 2\nz := * 3\nprint(y)", "print(y)", []string{
+			"2:3: expected ; or newline after statement, got :=",
+			"3:6: no prefix parse function for * found",
+		}},
+		{"var = 5\nvar b = 2\nvar c 3", "var b = 2", []string{
+			"1:5: expected token IDENT, got =",
+			"3:7: expected token =, got INT",
+		}},
+		{"func f(a) {\n  x := )\n  y := 2\n}\nq := ]\nf(1)", "func f (a) y := 2f(1)", []string{
+			"2:8: no prefix parse function for ) found",
+			"5:6: no prefix parse function for ] found",
+		}},
+		{"if (true) { x := ) } else { y := ] }", "iftrue else ", []string{
+			"1:18: no prefix parse function for ) found",
+			"1:34: no prefix parse function for ] found",
+		}},
+		{"{1: }\nx := 1", "x := 1", []string{
+			"1:5: no prefix parse function for } found",
+		}},
+		{"func f() { x := 1 }}\nw := *", "func f () x := 1", []string{
+			"1:20: no prefix parse function for } found",
+			"2:6: no prefix parse function for * found",
+		}},
+		{"switch x {\ncase +:\n  y := ]\ncase 2:\n  z := )\n}\nw := 1", "w := 1", []string{
+			"2:6: no prefix parse function for + found",
+			"3:8: no prefix parse function for ] found",
+			"5:8: no prefix parse function for ) found",
+		}},
+		{"const (\n  A = +\n  B\n  C = )\n)\nD := 1", "D := 1", []string{
+			"2:7: no prefix parse function for + found",
+			"4:7: no prefix parse function for ) found",
+		}},
+		{"for i := 0; i < ; i++ {\n x := ]\n}\ny := 1", "y := 1", []string{
+			"1:17: no prefix parse function for ; found",
+			"2:7: no prefix parse function for ] found",
+		}},
+		{"x := [1, 2\ny := 3", "y := 3", []string{
+			"1:11: expected token ], got ;",
+		}},
+		{"a b c\nd e\nf", "f", []string{
+			"1:3: expected ; or newline after statement, got IDENT",
+			"2:3: expected ; or newline after statement, got IDENT",
+		}},
+		{"f(1, 2", "", []string{
+			"1:7: expected token ), got EOF",
+		}},
+		// 出错语句自身的 { } 被整体跳过
+		{"if { }\nx := 1", "x := 1", []string{
+			"1:4: missing condition in if statement",
+		}},
+		{"if x := 1 { }\nx := 1", "x := 1", []string{
+			"1:4: expected if condition, got x := 1",
+		}},
+		{"func f(a = 1, b) {}\nx := 1", "x := 1", []string{
+			"1:15: parameter b without default follows parameter with default",
+		}},
+	}
+	for _, tt := range tests {
+		p := NewParser(lexer.NewLexer(tt.input))
+		v := p.ParseProgram()
+		assert.Equal(t, tt.errs, p.Errors(), tt.input)
+		assert.Equal(t, tt.expect, v.String(), tt.input)
+	}
+
+	// 出错的语句不会在语法树中留下 nil 节点
+	for _, input := range []string{"-", "!(", "1 + ", "f(", "x[", "{1:", "[1,", "if (", "func (", "macro(", "x := ", "a, b = 1,", "return +"} {
+		p := NewParser(lexer.NewLexer(input))
+		v := p.ParseProgram()
+		assert.NotEmpty(t, p.Errors(), input)
+		assert.Equal(t, 0, len(v.Stmts), input)
+	}
+}
+
+func TestParser_readerLexer(t *testing.T) {
+	input := `
+	func add(a, b) {
+		return a + b
+	}
+	var c = add(1, 2 * 3)
+	`
+	p := NewParser(lexer.NewReaderLexer(iotest.HalfReader(strings.NewReader(input))))
+	v := p.ParseProgram()
+	for _, s := range p.Errors() {
+		t.Errorf("parser error: %s", s)
+	}
+	assert.Equal(t, len(v.Stmts), 2)
+	assert.Equal(t, v.String(), NewParser(lexer.NewLexer(input)).ParseProgram().String())
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/songzhibin97/mini-interpreter/lexer"
 )
 
-const PROMPT = ">>>"
+const (
+	PROMPT   = ">>>"
+	CONTINUE = "..." // 多行语句尚未输入完整时的提示符
+)
 
 func Start(in io.Reader, out io.Writer) {
 	fmt.Println("Welcome to Mini-interpreter")
@@ -20,15 +23,15 @@ func Start(in io.Reader, out io.Writer) {
 	env := object.NewEnv(nil)
 	macroEnv := object.NewEnv(nil)
 	for {
-		_, _ = fmt.Fprintf(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		// 词法解析器按需逐行读取输入,括号未闭合时继续读取后续行,直到语句完整
+		r := &lineReader{scanner: scanner, out: out}
+		l := lexer.NewReaderLexer(r)
+		r.incomplete = l.Incomplete
+		p := parser.NewParser(l)
+		program := p.ParseProgram()
+		if r.lines == 0 {
 			return
 		}
-		line := scanner.Text()
-		//_, _ = io.WriteString(out, "input >>> "+line+"\r\n")
-		p := parser.NewParser(lexer.NewLexer(line))
-		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
 			for _, s := range p.Errors() {
 				_, _ = io.WriteString(out, "\t"+s+"\r\n")
@@ -42,3 +45,34 @@ func Start(in io.Reader, out io.Writer) {
 		//_, _ = io.WriteString(out, e.Inspect()+"\r\n")
 	}
 }
+
+// lineReader 为词法解析器逐行提供一条语句的输入:
+// 第一行总是读取,之后仅在词法解析器认为输入不完整(存在未闭合的括号、原始字符串或块注释)时继续读取
+type lineReader struct {
+	scanner    *bufio.Scanner
+	out        io.Writer
+	incomplete func() bool
+	lines      int    // 已读取的行数
+	buf        string // 当前行尚未交给词法解析器的部分
+}
+
+func (r *lineReader) Read(b []byte) (int, error) {
+	if r.buf == "" {
+		prompt := PROMPT
+		if r.lines > 0 {
+			if !r.incomplete() {
+				return 0, io.EOF
+			}
+			prompt = CONTINUE
+		}
+		_, _ = fmt.Fprintf(r.out, prompt)
+		if !r.scanner.Scan() {
+			return 0, io.EOF
+		}
+		r.lines++
+		r.buf = r.scanner.Text() + "\n"
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,36 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStart(t *testing.T) {
+	input := strings.Join([]string{
+		"func f() {",
+		"\treturn 1",
+		"}",
+		"x := `a",
+		"b`",
+		"/* c",
+		"*/",
+		"",
+		"x +",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+	assert.Equal(t, PROMPT+CONTINUE+CONTINUE+
+		PROMPT+CONTINUE+
+		PROMPT+CONTINUE+
+		PROMPT+
+		PROMPT+"\t2:1: no prefix parse function for EOF found\r\n"+
+		PROMPT, out.String())
+
+	// 输入在语句完整之前结束
+	out.Reset()
+	Start(strings.NewReader("f(1,\n"), &out)
+	assert.Equal(t, PROMPT+CONTINUE+"\t2:1: no prefix parse function for EOF found\r\n"+PROMPT, out.String())
+}
@@ -0,0 +1,212 @@
+package token
+
+// copy go/token/position.go (offsets and columns are counted in runes)
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// Positions
+
+// Position describes an arbitrary source position
+// including the file, line, and column location.
+// A Position is valid if the line number is > 0.
+type Position struct {
+	Filename string // filename, if any
+	Offset   int    // offset, starting at 0 (in runes)
+	Line     int    // line number, starting at 1
+	Column   int    // column number, starting at 1 (in runes)
+}
+
+// IsValid reports whether the position is valid.
+func (pos *Position) IsValid() bool { return pos.Line > 0 }
+
+// String returns a string in one of several forms:
+//
+//	file:line:column    valid position with file name
+//	line:column         valid position without file name
+//	file                invalid position with file name
+//	-                   invalid position without file name
+func (pos Position) String() string {
+	s := pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// -----------------------------------------------------------------------------
+// File
+
+// A File is a handle for a file belonging to a FileSet.
+// A File has a name, size, and line offset table.
+type File struct {
+	name string // file name as provided to AddFile
+	base int    // Pos value range for this file is [base...base+size]
+	size int    // file size as provided to AddFile, < 0 if unknown
+
+	// lines is protected by mutex
+	mutex sync.Mutex
+	lines []int // lines contains the offset of the first character for each line (the first entry is always 0)
+}
+
+// Name returns the file name of file f as registered with AddFile.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the base offset of file f as registered with AddFile.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the size of file f as registered with AddFile.
+// A negative size means the file is read incrementally and its
+// size is not known in advance.
+func (f *File) Size() int {
+	return f.size
+}
+
+// LineCount returns the number of lines in file f.
+func (f *File) LineCount() int {
+	f.mutex.Lock()
+	n := len(f.lines)
+	f.mutex.Unlock()
+	return n
+}
+
+// AddLine adds the line offset for a new line.
+// The line offset must be larger than the offset for the previous line
+// and smaller than the file size; otherwise the line offset is ignored.
+func (f *File) AddLine(offset int) {
+	f.mutex.Lock()
+	if i := len(f.lines); (i == 0 || f.lines[i-1] < offset) && (f.size < 0 || offset < f.size) {
+		f.lines = append(f.lines, offset)
+	}
+	f.mutex.Unlock()
+}
+
+// Pos returns the Pos value for the given file offset;
+// the offset must be >= 0.
+func (f *File) Pos(offset int) Pos {
+	if offset < 0 || (f.size >= 0 && offset > f.size) {
+		panic("illegal file offset")
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the offset for the given file position p;
+// p must be a valid Pos value in that file.
+func (f *File) Offset(p Pos) int {
+	if int(p) < f.base || (f.size >= 0 && int(p) > f.base+f.size) {
+		panic("illegal Pos value")
+	}
+	return int(p) - f.base
+}
+
+// Line returns the line number for the given file position p;
+// p must be a Pos value in that file or NoPos.
+func (f *File) Line(p Pos) int {
+	return f.Position(p).Line
+}
+
+// Position returns the Position value for the given file position p.
+// Calling f.Position(p) is equivalent to calling fset.Position(p)
+// where fset is the FileSet f belongs to.
+func (f *File) Position(p Pos) (pos Position) {
+	if p == NoPos {
+		return
+	}
+	offset := f.Offset(p)
+	pos.Filename = f.name
+	pos.Offset = offset
+
+	f.mutex.Lock()
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	if i >= 0 {
+		pos.Line, pos.Column = i+1, offset-f.lines[i]+1
+	}
+	f.mutex.Unlock()
+	return
+}
+
+// -----------------------------------------------------------------------------
+// FileSet
+
+// A FileSet represents a set of source files.
+// Methods of file sets are synchronized; multiple goroutines
+// may invoke them concurrently.
+//
+// The Pos values of the files in a set never overlap, so a single
+// Pos identifies both the file and the location inside of it.
+type FileSet struct {
+	mutex sync.Mutex
+	base  int     // base offset for the next file, < 0 once a file of unknown size was added
+	files []*File // list of files in the order added to the set
+}
+
+// NewFileSet creates a new file set.
+func NewFileSet() *FileSet {
+	return &FileSet{
+		base: 1, // 0 == NoPos
+	}
+}
+
+// AddFile adds a new file with a given filename and file size
+// to the file set s and returns the file. Multiple files may have
+// the same name. A negative size marks a file read incrementally
+// (e.g. from an io.Reader); such a file must be the last one added
+// to the set, because its Pos range is open-ended.
+func (s *FileSet) AddFile(filename string, size int) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.base < 0 {
+		panic("token: cannot add a file after a file of unknown size")
+	}
+	f := &File{name: filename, base: s.base, size: size, lines: []int{0}}
+	if size < 0 {
+		s.base = -1
+	} else {
+		// +1 because EOF also has a position
+		s.base += size + 1
+	}
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file that contains the position p.
+// If no such file is found (for instance for p == NoPos),
+// the result is nil.
+func (s *FileSet) File(p Pos) *File {
+	if p == NoPos {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 {
+		return nil
+	}
+	f := s.files[i]
+	if f.size >= 0 && int(p) > f.base+f.size {
+		return nil
+	}
+	return f
+}
+
+// Position converts a Pos p in the fileset into a Position value.
+func (s *FileSet) Position(p Pos) (pos Position) {
+	if f := s.File(p); f != nil {
+		return f.Position(p)
+	}
+	return
+}
@@ -0,0 +1,28 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSet_Position(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.mini", 10)
+	a.AddLine(4)
+	b := fset.AddFile("b.mini", 5)
+	b.AddLine(2)
+
+	assert.Equal(t, "a.mini:1:3", fset.Position(a.Pos(2)).String())
+	assert.Equal(t, "a.mini:2:1", fset.Position(a.Pos(4)).String())
+	assert.Equal(t, "b.mini:1:1", fset.Position(b.Pos(0)).String())
+	assert.Equal(t, "b.mini:2:3", fset.Position(b.Pos(4)).String())
+	assert.Equal(t, a, fset.File(a.Pos(10)))
+	assert.Equal(t, b, fset.File(b.Pos(0)))
+	assert.Nil(t, fset.File(NoPos))
+	assert.Equal(t, "-", fset.Position(NoPos).String())
+
+	c := NewFileSet().AddFile("", -1)
+	c.AddLine(100)
+	assert.Equal(t, "2:6", c.Position(c.Pos(105)).String())
+}
@@ -26,6 +26,7 @@ func (p Pos) IsValid() bool {
 type Token struct {
 	Type  Type
 	Value string
+	Pos   Pos // 词法单元起始位置,通过 File/FileSet 换算为行列号
 }
 
 func NewToken(tp Type, value string) *Token {
@@ -54,6 +55,11 @@ const (
 	IMAG   // 123.45i
 	CHAR   // 'a'
 	STRING // "abc"
+
+	// 插值字符串 "a${x}b${y}c" 依次拆分为 STRING_HEAD、表达式、STRING_MID、表达式、STRING_TAIL
+	STRING_HEAD // "a${
+	STRING_MID  // }b${
+	STRING_TAIL // }c"
 	literal_end
 
 	operator_beg
@@ -166,6 +172,10 @@ var tokens = [...]string{
 	CHAR:   "CHAR",
 	STRING: "STRING",
 
+	STRING_HEAD: "STRING_HEAD",
+	STRING_MID:  "STRING_MID",
+	STRING_TAIL: "STRING_TAIL",
+
 	ADD: "+",
 	SUB: "-",
 	MUL: "*",
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/songzhibin97/mini-interpreter/lexer"
+	"github.com/songzhibin97/mini-interpreter/token"
+)
+
+// tokenJSON tokens -json 输出的单个词法单元
+type tokenJSON struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Filename string `json:"filename,omitempty"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// tokens 实现 `mini tokens [-json] [-comments] <file>` 子命令,
+// 输出文件中每个词法单元的位置、类型与字面值. file 为 - 时读取标准输入
+func tokens(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	jsonOutput := fs.Bool("json", false, "output tokens as a JSON array")
+	comments := fs.Bool("comments", false, "include COMMENT tokens")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: mini tokens [-json] [-comments] <file>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	filename, src := fs.Arg(0), stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer f.Close()
+		src = f
+	}
+
+	var mode lexer.Mode
+	if *comments {
+		mode |= lexer.ScanComments
+	}
+	fset := token.NewFileSet()
+	l := lexer.NewReaderLexer(src, lexer.WithFile(fset.AddFile(filename, -1)), lexer.WithMode(mode))
+
+	if *jsonOutput {
+		all := l.All()
+		list := make([]tokenJSON, 0, len(all))
+		for _, tk := range all {
+			pos := fset.Position(tk.Pos)
+			list = append(list, tokenJSON{
+				Type:     tk.Type.String(),
+				Value:    tk.Value,
+				Filename: pos.Filename,
+				Offset:   pos.Offset,
+				Line:     pos.Line,
+				Column:   pos.Column,
+			})
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(list); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 1
+		}
+	} else {
+		for tk := l.NextToken(); ; tk = l.NextToken() {
+			_, _ = fmt.Fprintf(stdout, "%s\t%s\t%s\n", fset.Position(tk.Pos), tk.Type, strconv.Quote(tk.Value))
+			if tk.Type == token.EOF {
+				break
+			}
+		}
+	}
+
+	for _, err := range l.Errors() {
+		_, _ = fmt.Fprintln(stderr, err)
+	}
+	if len(l.Errors()) != 0 {
+		return 1
+	}
+	return 0
+}
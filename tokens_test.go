@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runTokens(args []string, stdin string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := tokens(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func Test_tokens(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "demo.mini")
+	if err := ioutil.WriteFile(file, []byte("var a = 1.5 // c\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runTokens([]string{file}, "")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, strings.Join([]string{
+		file + ":1:1\tvar\t\"var\"",
+		file + ":1:5\tIDENT\t\"a\"",
+		file + ":1:7\t=\t\"=\"",
+		file + ":1:9\tFLOAT\t\"1.5\"",
+		file + ":1:17\t;\t\"\\n\"",
+		file + ":2:1\tEOF\t\"\"",
+	}, "\n")+"\n", stdout)
+
+	// 读取标准输入,并输出注释
+	code, stdout, _ = runTokens([]string{"-comments", "-"}, "a // c\n")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, strings.Join([]string{
+		"-:1:1\tIDENT\t\"a\"",
+		"-:1:3\tCOMMENT\t\"// c\"",
+		"-:1:7\t;\t\"\\n\"",
+		"-:2:1\tEOF\t\"\"",
+	}, "\n")+"\n", stdout)
+
+	code, stdout, _ = runTokens([]string{"-json", "-"}, "x")
+	assert.Equal(t, 0, code)
+	var list []tokenJSON
+	assert.Nil(t, json.Unmarshal([]byte(stdout), &list))
+	assert.Equal(t, []tokenJSON{
+		{Type: "IDENT", Value: "x", Filename: "-", Offset: 0, Line: 1, Column: 1},
+		{Type: "EOF", Value: "", Filename: "-", Offset: 1, Line: 1, Column: 2},
+	}, list)
+}
+
+func Test_tokensErrors(t *testing.T) {
+	// 用法错误
+	for _, args := range [][]string{nil, {"a", "b"}, {"-unknown", "-"}} {
+		code, _, stderr := runTokens(args, "")
+		assert.Equal(t, 2, code, args)
+		assert.Contains(t, stderr, "usage: mini tokens", args)
+	}
+
+	// 词法错误
+	code, stdout, stderr := runTokens([]string{"-"}, `"abc`)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "EOF")
+	assert.Equal(t, "-:1:1: string literal not terminated\n", stderr)
+
+	code, _, stderr = runTokens([]string{filepath.Join(t.TempDir(), "missing.mini")}, "")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing.mini")
+}